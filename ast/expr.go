@@ -1,23 +1,36 @@
 package ast
 
-import "github.com/0xsuk/golox/token"
+import (
+	"github.com/0xsuk/golox/token"
+	"github.com/0xsuk/golox/value"
+)
 
 type Expr interface {
 	Accept(visitor ExprVisitor)
 }
 type ExprVisitor interface {
 	visitAssignExpr(expr *AssignExpr)
+	visitCompoundAssignExpr(expr *CompoundAssignExpr)
 	visitBinaryExpr(expr *BinaryExpr)
 	visitTernaryExpr(expr *TernaryExpr)
 	visitCallExpr(expr *CallExpr)
 	visitGetExpr(expr *GetExpr)
 	visitGroupingExpr(expr *GroupingExpr)
+	visitInterpolationExpr(expr *InterpolationExpr)
+	visitIndexExpr(expr *IndexExpr)
+	visitIndexSetExpr(expr *IndexSetExpr)
+	visitLambdaExpr(expr *LambdaExpr)
+	visitListExpr(expr *ListExpr)
 	visitLiteralExpr(expr *LiteralExpr)
 	visitLogicalExpr(expr *LogicalExpr)
+	visitMapExpr(expr *MapExpr)
+	visitOptionalChainExpr(expr *OptionalChainExpr)
 	visitSetExpr(expr *SetExpr)
+	visitSliceExpr(expr *SliceExpr)
 	visitSuperExpr(expr *SuperExpr)
 	visitThisExpr(expr *ThisExpr)
 	visitUnaryExpr(expr *UnaryExpr)
+	visitUpdateExpr(expr *UpdateExpr)
 	visitVariableExpr(expr *VariableExpr)
 }
 type AssignExpr struct {
@@ -32,6 +45,17 @@ func (expr *AssignExpr) Accept(visitor ExprVisitor) {
 	visitor.visitAssignExpr(expr)
 }
 
+type CompoundAssignExpr struct {
+	Expr
+	Target   Expr
+	Operator token.Token
+	Value    Expr
+}
+
+func (expr *CompoundAssignExpr) Accept(visitor ExprVisitor) {
+	visitor.visitCompoundAssignExpr(expr)
+}
+
 type BinaryExpr struct {
 	Expr
 	Left     Expr
@@ -69,8 +93,9 @@ func (expr *CallExpr) Accept(visitor ExprVisitor) {
 
 type GetExpr struct {
 	Expr
-	Object Expr
-	Name   token.Token
+	Object   Expr
+	Name     token.Token
+	Optional bool
 }
 
 func (expr *GetExpr) Accept(visitor ExprVisitor) {
@@ -86,9 +111,62 @@ func (expr *GroupingExpr) Accept(visitor ExprVisitor) {
 	visitor.visitGroupingExpr(expr)
 }
 
+type InterpolationExpr struct {
+	Expr
+	Parts []Expr
+}
+
+func (expr *InterpolationExpr) Accept(visitor ExprVisitor) {
+	visitor.visitInterpolationExpr(expr)
+}
+
+type IndexExpr struct {
+	Expr
+	Object  Expr
+	Bracket token.Token
+	Index   Expr
+}
+
+func (expr *IndexExpr) Accept(visitor ExprVisitor) {
+	visitor.visitIndexExpr(expr)
+}
+
+type IndexSetExpr struct {
+	Expr
+	Object  Expr
+	Bracket token.Token
+	Index   Expr
+	Value   Expr
+}
+
+func (expr *IndexSetExpr) Accept(visitor ExprVisitor) {
+	visitor.visitIndexSetExpr(expr)
+}
+
+type LambdaExpr struct {
+	Expr
+	Keyword token.Token
+	Params  []token.Token
+	Body    []Stmt
+}
+
+func (expr *LambdaExpr) Accept(visitor ExprVisitor) {
+	visitor.visitLambdaExpr(expr)
+}
+
+type ListExpr struct {
+	Expr
+	Bracket  token.Token
+	Elements []Expr
+}
+
+func (expr *ListExpr) Accept(visitor ExprVisitor) {
+	visitor.visitListExpr(expr)
+}
+
 type LiteralExpr struct {
 	Expr
-	Value interface{}
+	Value value.Value
 }
 
 func (expr *LiteralExpr) Accept(visitor ExprVisitor) {
@@ -106,6 +184,26 @@ func (expr *LogicalExpr) Accept(visitor ExprVisitor) {
 	visitor.visitLogicalExpr(expr)
 }
 
+type MapExpr struct {
+	Expr
+	Brace  token.Token
+	Keys   []Expr
+	Values []Expr
+}
+
+func (expr *MapExpr) Accept(visitor ExprVisitor) {
+	visitor.visitMapExpr(expr)
+}
+
+type OptionalChainExpr struct {
+	Expr
+	Expression Expr
+}
+
+func (expr *OptionalChainExpr) Accept(visitor ExprVisitor) {
+	visitor.visitOptionalChainExpr(expr)
+}
+
 type SetExpr struct {
 	Expr
 	Object Expr
@@ -117,6 +215,18 @@ func (expr *SetExpr) Accept(visitor ExprVisitor) {
 	visitor.visitSetExpr(expr)
 }
 
+type SliceExpr struct {
+	Expr
+	Object  Expr
+	Bracket token.Token
+	Start   Expr
+	End     Expr
+}
+
+func (expr *SliceExpr) Accept(visitor ExprVisitor) {
+	visitor.visitSliceExpr(expr)
+}
+
 type SuperExpr struct {
 	Expr
 	Keyword token.Token
@@ -148,6 +258,17 @@ func (expr *UnaryExpr) Accept(visitor ExprVisitor) {
 	visitor.visitUnaryExpr(expr)
 }
 
+type UpdateExpr struct {
+	Expr
+	Target   Expr
+	Operator token.Token
+	Prefix   bool
+}
+
+func (expr *UpdateExpr) Accept(visitor ExprVisitor) {
+	visitor.visitUpdateExpr(expr)
+}
+
 type VariableExpr struct {
 	Expr
 	Name     token.Token
@@ -0,0 +1,9 @@
+package ast
+
+// MatchCase is one `case p1, p2 if guard => body` arm of a MatchStmt.
+// Guard is nil when the case has none.
+type MatchCase struct {
+	Patterns []Pattern
+	Guard    Expr
+	Body     Stmt
+}
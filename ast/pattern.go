@@ -0,0 +1,79 @@
+package ast
+
+import (
+	"github.com/0xsuk/golox/token"
+	"github.com/0xsuk/golox/value"
+)
+
+type Pattern interface {
+	Accept(visitor PatternVisitor)
+}
+type PatternVisitor interface {
+	visitLiteralPattern(pattern *LiteralPattern)
+	visitWildcardPattern(pattern *WildcardPattern)
+	visitBindingPattern(pattern *BindingPattern)
+	visitListPattern(pattern *ListPattern)
+	visitMapPattern(pattern *MapPattern)
+	visitInstancePattern(pattern *InstancePattern)
+}
+type LiteralPattern struct {
+	Pattern
+	Token token.Token
+	Value value.Value
+}
+
+func (pattern *LiteralPattern) Accept(visitor PatternVisitor) {
+	visitor.visitLiteralPattern(pattern)
+}
+
+type WildcardPattern struct {
+	Pattern
+	Token token.Token
+}
+
+func (pattern *WildcardPattern) Accept(visitor PatternVisitor) {
+	visitor.visitWildcardPattern(pattern)
+}
+
+type BindingPattern struct {
+	Pattern
+	Name     token.Token
+	EnvIndex int
+	EnvDepth int
+}
+
+func (pattern *BindingPattern) Accept(visitor PatternVisitor) {
+	visitor.visitBindingPattern(pattern)
+}
+
+type ListPattern struct {
+	Pattern
+	Bracket  token.Token
+	Elements []Pattern
+}
+
+func (pattern *ListPattern) Accept(visitor PatternVisitor) {
+	visitor.visitListPattern(pattern)
+}
+
+type MapPattern struct {
+	Pattern
+	Brace  token.Token
+	Keys   []value.Value
+	Values []Pattern
+}
+
+func (pattern *MapPattern) Accept(visitor PatternVisitor) {
+	visitor.visitMapPattern(pattern)
+}
+
+type InstancePattern struct {
+	Pattern
+	Class    VariableExpr
+	Fields   []token.Token
+	Patterns []Pattern
+}
+
+func (pattern *InstancePattern) Accept(visitor PatternVisitor) {
+	visitor.visitInstancePattern(pattern)
+}
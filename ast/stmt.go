@@ -9,12 +9,16 @@ type StmtVisitor interface {
 	visitBlockStmt(stmt *BlockStmt)
 	visitClassStmt(stmt *ClassStmt)
 	visitExpressionStmt(stmt *ExpressionStmt)
+	visitForInStmt(stmt *ForInStmt)
 	visitFunctionStmt(stmt *FunctionStmt)
+	visitMatchStmt(stmt *MatchStmt)
 	visitIfStmt(stmt *IfStmt)
 	visitPrintStmt(stmt *PrintStmt)
 	visitReturnStmt(stmt *ReturnStmt)
 	visitContinueStmt(stmt *ContinueStmt)
 	visitBreakStmt(stmt *BreakStmt)
+	visitThrowStmt(stmt *ThrowStmt)
+	visitTryStmt(stmt *TryStmt)
 	visitVarStmt(stmt *VarStmt)
 	visitWhileStmt(stmt *WhileStmt)
 }
@@ -47,6 +51,18 @@ func (stmt *ExpressionStmt) Accept(visitor StmtVisitor) {
 	visitor.visitExpressionStmt(stmt)
 }
 
+type ForInStmt struct {
+	Stmt
+	Keyword  token.Token
+	Name     token.Token
+	Iterable Expr
+	Body     Stmt
+}
+
+func (stmt *ForInStmt) Accept(visitor StmtVisitor) {
+	visitor.visitForInStmt(stmt)
+}
+
 type FunctionStmt struct {
 	Stmt
 	Name   token.Token
@@ -58,6 +74,17 @@ func (stmt *FunctionStmt) Accept(visitor StmtVisitor) {
 	visitor.visitFunctionStmt(stmt)
 }
 
+type MatchStmt struct {
+	Stmt
+	Keyword token.Token
+	Subject Expr
+	Cases   []MatchCase
+}
+
+func (stmt *MatchStmt) Accept(visitor StmtVisitor) {
+	visitor.visitMatchStmt(stmt)
+}
+
 type IfStmt struct {
 	Stmt
 	Condition  Expr
@@ -106,6 +133,29 @@ func (stmt *BreakStmt) Accept(visitor StmtVisitor) {
 	visitor.visitBreakStmt(stmt)
 }
 
+type ThrowStmt struct {
+	Stmt
+	Keyword token.Token
+	Value   Expr
+}
+
+func (stmt *ThrowStmt) Accept(visitor StmtVisitor) {
+	visitor.visitThrowStmt(stmt)
+}
+
+type TryStmt struct {
+	Stmt
+	Keyword token.Token
+	Body    []Stmt
+	Name    token.Token
+	Catch   []Stmt
+	Finally []Stmt
+}
+
+func (stmt *TryStmt) Accept(visitor StmtVisitor) {
+	visitor.visitTryStmt(stmt)
+}
+
 type VarStmt struct {
 	Stmt
 	Name        token.Token
@@ -3,35 +3,36 @@ package env
 import (
 	"github.com/0xsuk/golox/runtime_error"
 	"github.com/0xsuk/golox/token"
+	"github.com/0xsuk/golox/value"
 )
 
 type Environment struct {
-	values        map[string]interface{}
+	values        map[string]value.Value
 	enclosing     *Environment
-	indexedValues []interface{} //TODO:?
+	indexedValues []value.Value //TODO:?
 }
 
 type uninitialized struct{} //TODO:?
 
-var needsInitialization = &uninitialized{}
+var needsInitialization = value.Object(&uninitialized{})
 
 func New(env *Environment) *Environment {
 	return NewSized(env, 0)
 }
 
 func NewSized(env *Environment, size int) *Environment {
-	return &Environment{values: make(map[string]interface{}), enclosing: env, indexedValues: make([]interface{}, size)}
+	return &Environment{values: make(map[string]value.Value), enclosing: env, indexedValues: make([]value.Value, size)}
 }
 
 func NewGlobal() *Environment {
 	return New(nil)
 }
 
-func (e *Environment) Define(name string, value interface{}, index int) {
+func (e *Environment) Define(name string, val value.Value, index int) {
 	if index == -1 {
-		e.values[name] = value
+		e.values[name] = val
 	} else {
-		e.indexedValues[index] = value
+		e.indexedValues[index] = val
 	}
 }
 
@@ -42,7 +43,7 @@ func (e *Environment) DefineUninitialized(name string, index int) {
 		e.indexedValues[index] = needsInitialization
 	}
 }
-func (e *Environment) Get(name token.Token, index int) interface{} {
+func (e *Environment) Get(name token.Token, index int) value.Value {
 	if index != -1 {
 		return e.indexedValues[index]
 	}
@@ -51,7 +52,7 @@ func (e *Environment) Get(name token.Token, index int) interface{} {
 	if ok {
 		if v == needsInitialization {
 			runtime_error.ReportAtLine(name.Line, "Uninitialized variable access: "+name.Lexeme)
-			return nil
+			return value.Nil
 		}
 		return v
 	}
@@ -60,33 +61,33 @@ func (e *Environment) Get(name token.Token, index int) interface{} {
 	}
 
 	runtime_error.ReportAtLine(name.Line, "Undefined variable '"+name.Lexeme+"'")
-	return nil
+	return value.Nil
 }
 
-func (e *Environment) GetAt(distance int, name token.Token, index int) interface{} {
+func (e *Environment) GetAt(distance int, name token.Token, index int) value.Value {
 	return e.Ancestor(distance).Get(name, index)
 }
 
-func (e *Environment) Assign(name token.Token, index int, value interface{}) {
+func (e *Environment) Assign(name token.Token, index int, val value.Value) {
 	if index != -1 {
-		e.indexedValues[index] = value
+		e.indexedValues[index] = val
 		return
 	}
 
 	if _, ok := e.values[name.Lexeme]; ok {
-		e.values[name.Lexeme] = value
+		e.values[name.Lexeme] = val
 		return
 	}
 	if e.enclosing != nil {
-		e.enclosing.Assign(name, index, value)
+		e.enclosing.Assign(name, index, val)
 		return
 	}
 
 	runtime_error.ReportAtLine(name.Line, "Undefined variable '"+name.Lexeme+"'")
 }
 
-func (e *Environment) AssignAt(distance int, index int, name token.Token, value interface{}) {
-	e.Ancestor(distance).Assign(name, index, value)
+func (e *Environment) AssignAt(distance int, index int, name token.Token, val value.Value) {
+	e.Ancestor(distance).Assign(name, index, val)
 }
 
 func (e *Environment) Ancestor(distance int) *Environment {
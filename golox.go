@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strconv"
 
+	"github.com/0xsuk/golox/optimizer"
 	"github.com/0xsuk/golox/parse_error"
 	"github.com/0xsuk/golox/parser"
 	"github.com/0xsuk/golox/runtime_error"
@@ -14,6 +16,29 @@ import (
 	"github.com/0xsuk/golox/semantic_error"
 )
 
+// optLevel is 1 unless the AST optimiser is turned off with -O0
+var optLevel = 1
+
+// optFlag is a boolean flag such as -O1 that sets optLevel to its level,
+// so the last of -O0 and -O1 on the command line wins
+type optFlag int
+
+func (f optFlag) String() string {
+	return strconv.FormatBool(optLevel == int(f))
+}
+
+func (f optFlag) Set(s string) error {
+	on, err := strconv.ParseBool(s)
+	if err == nil && on {
+		optLevel = int(f)
+	}
+	return err
+}
+
+func (f optFlag) IsBoolFlag() bool {
+	return true
+}
+
 func check(err error) {
 	if err != nil {
 		panic(err)
@@ -54,14 +79,21 @@ func run(src string) {
 	}
 
 	parser := parser.New(tokens)
-	_ = parser.Parse()
+	statements := parser.Parse()
 	if parse_error.HadError {
 		return
 	}
+
+	if optLevel >= 1 {
+		statements = optimizer.Optimize(statements)
+	}
+	_ = statements
 }
 
 func main() {
 	flag.String("file", "", "the script file to execute")
+	flag.Var(optFlag(0), "O0", "disable the AST optimiser")
+	flag.Var(optFlag(1), "O1", "fold constants and remove dead code")
 	flag.Parse()
 
 	args := flag.Args()
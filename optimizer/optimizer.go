@@ -0,0 +1,352 @@
+package optimizer
+
+import (
+	"math"
+
+	"github.com/0xsuk/golox/ast"
+	"github.com/0xsuk/golox/token"
+	"github.com/0xsuk/golox/value"
+)
+
+// Optimize folds constant expressions and drops unreachable statements.
+// Only expressions that cannot fail are folded, so anything that would raise
+// a runtime error is left in place with its tokens and line info intact.
+func Optimize(statements []ast.Stmt) []ast.Stmt {
+	return optimizeStmts(statements)
+}
+
+func optimizeStmts(statements []ast.Stmt) []ast.Stmt {
+	optimized := make([]ast.Stmt, 0, len(statements))
+
+	for _, stmt := range statements {
+		stmt = optimizeStmt(stmt)
+		if stmt == nil {
+			continue
+		}
+		optimized = append(optimized, stmt)
+
+		switch stmt.(type) {
+		case *ast.ReturnStmt, *ast.BreakStmt, *ast.ContinueStmt, *ast.ThrowStmt:
+			// anything after this in the same block is unreachable
+			return optimized
+		}
+	}
+	return optimized
+}
+
+// optimizeStmt returns nil when the statement can be removed altogether
+func optimizeStmt(stmt ast.Stmt) ast.Stmt {
+	switch s := stmt.(type) {
+	case *ast.BlockStmt:
+		s.Statements = optimizeStmts(s.Statements)
+	case *ast.ClassStmt:
+		for i := range s.Methods {
+			s.Methods[i].Body = optimizeStmts(s.Methods[i].Body)
+		}
+	case *ast.ExpressionStmt:
+		s.Expression = optimizeExpr(s.Expression)
+	case *ast.ForInStmt:
+		s.Iterable = optimizeExpr(s.Iterable)
+		s.Body = optimizeBranch(s.Body)
+	case *ast.FunctionStmt:
+		s.Body = optimizeStmts(s.Body)
+	case *ast.IfStmt:
+		s.Condition = optimizeExpr(s.Condition)
+		if lit, ok := s.Condition.(*ast.LiteralExpr); ok {
+			if lit.Value.IsTruthy() {
+				return optimizeStmt(s.ThenBranch)
+			}
+			return optimizeStmt(s.ElseBranch)
+		}
+		s.ThenBranch = optimizeBranch(s.ThenBranch)
+		if s.ElseBranch != nil {
+			s.ElseBranch = optimizeStmt(s.ElseBranch)
+		}
+	case *ast.MatchStmt:
+		s.Subject = optimizeExpr(s.Subject)
+		for i := range s.Cases {
+			if s.Cases[i].Guard != nil {
+				s.Cases[i].Guard = optimizeExpr(s.Cases[i].Guard)
+			}
+			s.Cases[i].Body = optimizeBranch(s.Cases[i].Body)
+		}
+	case *ast.PrintStmt:
+		s.Expression = optimizeExpr(s.Expression)
+	case *ast.ReturnStmt:
+		if s.Value != nil {
+			s.Value = optimizeExpr(s.Value)
+		}
+	case *ast.ThrowStmt:
+		s.Value = optimizeExpr(s.Value)
+	case *ast.TryStmt:
+		s.Body = optimizeStmts(s.Body)
+		if s.Catch != nil {
+			s.Catch = optimizeStmts(s.Catch)
+		}
+		if s.Finally != nil {
+			s.Finally = optimizeStmts(s.Finally)
+		}
+	case *ast.VarStmt:
+		if s.Initializer != nil {
+			s.Initializer = optimizeExpr(s.Initializer)
+		}
+	case *ast.WhileStmt:
+		s.Condition = optimizeExpr(s.Condition)
+		if lit, ok := s.Condition.(*ast.LiteralExpr); ok && !lit.Value.IsTruthy() {
+			return nil
+		}
+		s.Body = optimizeBranch(s.Body)
+	case nil:
+		return nil
+	}
+	return stmt
+}
+
+// optimizeBranch is optimizeStmt for places that need a statement to stay there
+func optimizeBranch(stmt ast.Stmt) ast.Stmt {
+	stmt = optimizeStmt(stmt)
+	if stmt == nil {
+		return &ast.BlockStmt{Statements: []ast.Stmt{}}
+	}
+	return stmt
+}
+
+func optimizeExpr(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.AssignExpr:
+		e.Value = optimizeExpr(e.Value)
+	case *ast.CompoundAssignExpr:
+		e.Target = optimizeExpr(e.Target)
+		e.Value = optimizeExpr(e.Value)
+	case *ast.BinaryExpr:
+		e.Left = optimizeExpr(e.Left)
+		e.Right = optimizeExpr(e.Right)
+		return foldBinary(e)
+	case *ast.TernaryExpr:
+		e.Condition = optimizeExpr(e.Condition)
+		e.Then = optimizeExpr(e.Then)
+		e.Else = optimizeExpr(e.Else)
+		if lit, ok := e.Condition.(*ast.LiteralExpr); ok {
+			if lit.Value.IsTruthy() {
+				return e.Then
+			}
+			return e.Else
+		}
+	case *ast.CallExpr:
+		e.Callee = optimizeExpr(e.Callee)
+		for i, arg := range e.Arguments {
+			e.Arguments[i] = optimizeExpr(arg)
+		}
+	case *ast.GetExpr:
+		e.Object = optimizeExpr(e.Object)
+	case *ast.GroupingExpr:
+		e.Expression = optimizeExpr(e.Expression)
+		if lit, ok := e.Expression.(*ast.LiteralExpr); ok {
+			return lit
+		}
+	case *ast.IndexExpr:
+		e.Object = optimizeExpr(e.Object)
+		e.Index = optimizeExpr(e.Index)
+	case *ast.IndexSetExpr:
+		e.Object = optimizeExpr(e.Object)
+		e.Index = optimizeExpr(e.Index)
+		e.Value = optimizeExpr(e.Value)
+	case *ast.InterpolationExpr:
+		return foldInterpolation(e)
+	case *ast.LambdaExpr:
+		e.Body = optimizeStmts(e.Body)
+	case *ast.ListExpr:
+		for i, element := range e.Elements {
+			e.Elements[i] = optimizeExpr(element)
+		}
+	case *ast.LogicalExpr:
+		e.Left = optimizeExpr(e.Left)
+		e.Right = optimizeExpr(e.Right)
+		if lit, ok := e.Left.(*ast.LiteralExpr); ok {
+			if e.Operator.Type == token.QMARKQMARK {
+				if lit.Value.IsNil() {
+					return e.Right
+				}
+				return lit
+			}
+			if (e.Operator.Type == token.OR) == lit.Value.IsTruthy() {
+				return lit
+			}
+			return e.Right
+		}
+	case *ast.MapExpr:
+		for i := range e.Keys {
+			e.Keys[i] = optimizeExpr(e.Keys[i])
+			e.Values[i] = optimizeExpr(e.Values[i])
+		}
+	case *ast.OptionalChainExpr:
+		e.Expression = optimizeExpr(e.Expression)
+	case *ast.SetExpr:
+		e.Object = optimizeExpr(e.Object)
+		e.Value = optimizeExpr(e.Value)
+	case *ast.SliceExpr:
+		e.Object = optimizeExpr(e.Object)
+		if e.Start != nil {
+			e.Start = optimizeExpr(e.Start)
+		}
+		if e.End != nil {
+			e.End = optimizeExpr(e.End)
+		}
+	case *ast.UnaryExpr:
+		e.Right = optimizeExpr(e.Right)
+		return foldUnary(e)
+	case *ast.UpdateExpr:
+		e.Target = optimizeExpr(e.Target)
+	}
+	return expr
+}
+
+func foldInterpolation(expr *ast.InterpolationExpr) ast.Expr {
+	text := ""
+	constant := true
+	for i, part := range expr.Parts {
+		expr.Parts[i] = optimizeExpr(part)
+		if lit, ok := expr.Parts[i].(*ast.LiteralExpr); ok {
+			text += lit.Value.String()
+		} else {
+			constant = false
+		}
+	}
+
+	if constant {
+		return &ast.LiteralExpr{Value: value.Object(text)}
+	}
+	return expr
+}
+
+func foldUnary(expr *ast.UnaryExpr) ast.Expr {
+	right, ok := expr.Right.(*ast.LiteralExpr)
+	if !ok {
+		return expr
+	}
+
+	switch expr.Operator.Type {
+	case token.BANG:
+		return &ast.LiteralExpr{Value: value.Bool(!right.Value.IsTruthy())}
+	case token.MINUS:
+		if right.Value.IsNumber() {
+			return &ast.LiteralExpr{Value: value.Number(-right.Value.AsNumber())}
+		}
+	case token.TILDE:
+		if n, ok := integral(right.Value); ok {
+			return &ast.LiteralExpr{Value: value.Number(float64(^n))}
+		}
+	}
+	return expr
+}
+
+func foldBinary(expr *ast.BinaryExpr) ast.Expr {
+	left, ok := expr.Left.(*ast.LiteralExpr)
+	if !ok {
+		return expr
+	}
+	if expr.Operator.Type == token.COMMA {
+		// a literal on the left of the comma operator has no effect
+		return expr.Right
+	}
+	right, ok := expr.Right.(*ast.LiteralExpr)
+	if !ok {
+		return expr
+	}
+	l, r := left.Value, right.Value
+
+	switch expr.Operator.Type {
+	case token.EQUALEQUAL:
+		return &ast.LiteralExpr{Value: value.Bool(l.Equals(r))}
+	case token.BANGEQUAL:
+		return &ast.LiteralExpr{Value: value.Bool(!l.Equals(r))}
+	case token.PLUS:
+		if l.IsString() && r.IsString() {
+			return &ast.LiteralExpr{Value: value.Object(l.AsString() + r.AsString())}
+		}
+	}
+
+	if !l.IsNumber() || !r.IsNumber() {
+		// type errors are reported at runtime with the operator's line
+		return expr
+	}
+	a, b := l.AsNumber(), r.AsNumber()
+
+	switch expr.Operator.Type {
+	case token.SLASH, token.SLASHSLASH, token.PERCENT:
+		if b == 0 {
+			// division by zero is the runtime's call, whatever the operator
+			return expr
+		}
+	}
+
+	switch expr.Operator.Type {
+	case token.PLUS:
+		return &ast.LiteralExpr{Value: value.Number(a + b)}
+	case token.MINUS:
+		return &ast.LiteralExpr{Value: value.Number(a - b)}
+	case token.STAR:
+		return &ast.LiteralExpr{Value: value.Number(a * b)}
+	case token.SLASH:
+		return &ast.LiteralExpr{Value: value.Number(a / b)}
+	case token.POWER:
+		return &ast.LiteralExpr{Value: value.Number(math.Pow(a, b))}
+	case token.SLASHSLASH:
+		return &ast.LiteralExpr{Value: value.Number(math.Floor(a / b))}
+	case token.PERCENT:
+		// floored, so that a == (a // b) * b + a % b
+		return &ast.LiteralExpr{Value: value.Number(a - b*math.Floor(a/b))}
+	case token.GREATER:
+		return &ast.LiteralExpr{Value: value.Bool(a > b)}
+	case token.GREATEREQUAL:
+		return &ast.LiteralExpr{Value: value.Bool(a >= b)}
+	case token.LESS:
+		return &ast.LiteralExpr{Value: value.Bool(a < b)}
+	case token.LESSEQUAL:
+		return &ast.LiteralExpr{Value: value.Bool(a <= b)}
+	}
+	return foldBitwise(expr, l, r)
+}
+
+// foldBitwise folds & | ^ << >> when both sides are integers,
+// anything else is a runtime error
+func foldBitwise(expr *ast.BinaryExpr, l value.Value, r value.Value) ast.Expr {
+	a, ok := integral(l)
+	if !ok {
+		return expr
+	}
+	b, ok := integral(r)
+	if !ok {
+		return expr
+	}
+
+	switch expr.Operator.Type {
+	case token.AMPERSAND:
+		return &ast.LiteralExpr{Value: value.Number(float64(a & b))}
+	case token.PIPE:
+		return &ast.LiteralExpr{Value: value.Number(float64(a | b))}
+	case token.CARET:
+		return &ast.LiteralExpr{Value: value.Number(float64(a ^ b))}
+	case token.LESSLESS:
+		if b >= 0 && b < 64 {
+			return &ast.LiteralExpr{Value: value.Number(float64(a << b))}
+		}
+	case token.GREATERGREATER:
+		if b >= 0 && b < 64 {
+			return &ast.LiteralExpr{Value: value.Number(float64(a >> b))}
+		}
+	}
+	return expr
+}
+
+// integral converts a number with no fractional part that fits in an int64
+func integral(v value.Value) (int64, bool) {
+	if !v.IsNumber() {
+		return 0, false
+	}
+	n := v.AsNumber()
+	if n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(n), true
+}
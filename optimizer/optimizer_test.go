@@ -0,0 +1,121 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/0xsuk/golox/ast"
+	"github.com/0xsuk/golox/parser"
+	"github.com/0xsuk/golox/scanner"
+	"github.com/0xsuk/golox/token"
+	"github.com/0xsuk/golox/value"
+)
+
+func optimizeExpression(t *testing.T, src string) ast.Expr {
+	sc := scanner.New(src + ";")
+	p := parser.New(sc.ScanTokens())
+	statements := Optimize(p.Parse())
+	if len(statements) != 1 {
+		t.Fatalf("%s: got %v statements", src, len(statements))
+	}
+	return statements[0].(*ast.ExpressionStmt).Expression
+}
+
+func TestFolding(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"2 ** 10 * 60", "61440"},
+		{"\"a\" + \"b\"", "ab"},
+		{"1 < 2 ? 3 : 4", "3"},
+		{"-7 // 2", "-4"},
+		{"-7 % 3", "2"},
+		{"nil ?? 5", "5"},
+		{"6 & 3 | 8", "10"},
+	}
+
+	for _, tt := range tests {
+		lit, ok := optimizeExpression(t, tt.src).(*ast.LiteralExpr)
+		if !ok {
+			t.Errorf("%s: not folded", tt.src)
+		} else if lit.Value.String() != tt.want {
+			t.Errorf("%s = %s, want %s", tt.src, lit.Value.String(), tt.want)
+		}
+	}
+}
+
+func TestNotFolded(t *testing.T) {
+	for _, src := range []string{"1 / 0", "1 // 0", "1 % 0", "\"a\" - 1", "1.5 & 1", "-nil"} {
+		if _, ok := optimizeExpression(t, src).(*ast.LiteralExpr); ok {
+			t.Errorf("%s: folded, want it left for the runtime", src)
+		}
+	}
+}
+
+func optimizeStatements(src string) []ast.Stmt {
+	sc := scanner.New(src)
+	p := parser.New(sc.ScanTokens())
+	return Optimize(p.Parse())
+}
+
+func TestUnreachableRemoved(t *testing.T) {
+	statements := optimizeStatements("fun () { return 1; f(); };")
+	lambda := statements[0].(*ast.ExpressionStmt).Expression.(*ast.LambdaExpr)
+	if len(lambda.Body) != 1 {
+		t.Errorf("function body has %v statements, want only the return", len(lambda.Body))
+	}
+
+	statements = optimizeStatements("for (var x in xs) { break; f(); }")
+	body := statements[0].(*ast.ForInStmt).Body.(*ast.BlockStmt)
+	if len(body.Statements) != 1 {
+		t.Errorf("loop body has %v statements, want only the break", len(body.Statements))
+	}
+
+	// only the rest of the inner block is unreachable
+	statements = optimizeStatements("fun () { { throw 1; g(); } h(); };")
+	lambda = statements[0].(*ast.ExpressionStmt).Expression.(*ast.LambdaExpr)
+	block := lambda.Body[0].(*ast.BlockStmt)
+	if len(block.Statements) != 1 || len(lambda.Body) != 2 {
+		t.Errorf("throw left %v statements in its block and %v after it, want 1 and 2", len(block.Statements), len(lambda.Body))
+	}
+}
+
+// the parser doesn't produce if or while yet, so these are built by hand
+func TestDeadBranches(t *testing.T) {
+	then := &ast.PrintStmt{Expression: &ast.LiteralExpr{Value: value.Number(1)}}
+	otherwise := &ast.PrintStmt{Expression: &ast.LiteralExpr{Value: value.Number(2)}}
+
+	statements := Optimize([]ast.Stmt{&ast.IfStmt{
+		Condition:  &ast.LiteralExpr{Value: value.Bool(true)},
+		ThenBranch: then,
+		ElseBranch: otherwise,
+	}})
+	if len(statements) != 1 || statements[0] != ast.Stmt(then) {
+		t.Errorf("if (true) left %#v, want the then branch", statements)
+	}
+
+	statements = Optimize([]ast.Stmt{&ast.IfStmt{
+		Condition:  &ast.BinaryExpr{Left: &ast.LiteralExpr{Value: value.Number(1)}, Operator: token.Token{Type: token.GREATER, Lexeme: ">"}, Right: &ast.LiteralExpr{Value: value.Number(2)}},
+		ThenBranch: then,
+		ElseBranch: otherwise,
+	}})
+	if len(statements) != 1 || statements[0] != ast.Stmt(otherwise) {
+		t.Errorf("if (1 > 2) left %#v, want the else branch", statements)
+	}
+
+	statements = Optimize([]ast.Stmt{&ast.IfStmt{
+		Condition:  &ast.LiteralExpr{Value: value.Nil},
+		ThenBranch: then,
+	}})
+	if len(statements) != 0 {
+		t.Errorf("if (nil) without else left %#v, want nothing", statements)
+	}
+
+	statements = Optimize([]ast.Stmt{&ast.WhileStmt{
+		Condition: &ast.LiteralExpr{Value: value.Bool(false)},
+		Body:      then,
+	}})
+	if len(statements) != 0 {
+		t.Errorf("while (false) left %#v, want nothing", statements)
+	}
+}
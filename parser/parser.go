@@ -1,19 +1,30 @@
 package parser
 
 import (
+	"fmt"
+
 	"github.com/0xsuk/golox/ast"
 	"github.com/0xsuk/golox/parse_error"
 	"github.com/0xsuk/golox/token"
+	"github.com/0xsuk/golox/value"
 )
 
+// maxArity caps both call arguments and function parameters, so that a
+// count always fits in a single byte operand
+const maxArity = 255
+
 type Parser struct {
 	tokens  []token.Token
 	current int
-	inloop  bool //TODO: ?
+	inloop  bool // whether break and continue are allowed here
+	inguard bool // whether '(a) =>' is the end of a case guard rather than an arrow function
+	// `_` variables parsed on the right of a |>, see pipeline
+	placeholders []*ast.VariableExpr
+	pipelines    int
 }
 
 func New(tokens []token.Token) Parser {
-	return Parser{tokens, 0, false}
+	return Parser{tokens: tokens, current: 0, inloop: false}
 }
 
 func (p *Parser) Parse() []ast.Stmt {
@@ -41,7 +52,8 @@ func (p *Parser) declaration() ast.Stmt {
 		stmt = nil
 	} else if p.match(token.VAR) {
 		stmt = nil
-	} else if p.match(token.FUN) {
+	} else if p.checkNext(token.IDENTIFIER) && p.match(token.FUN) {
+		// "fun (" is left to primary as a lambda expression
 	} else {
 		stmt = p.statement()
 	}
@@ -50,9 +62,247 @@ func (p *Parser) declaration() ast.Stmt {
 }
 
 func (p *Parser) statement() ast.Stmt {
+	// a '{' starting a statement is always a block, map literals there need parens
+	if p.match(token.LEFTBRACE) {
+		return &ast.BlockStmt{Statements: p.block()}
+	} else if p.match(token.FOR) {
+		return p.forInStatement()
+	} else if p.match(token.MATCH) {
+		return p.matchStatement()
+	} else if p.match(token.TRY) {
+		return p.tryStatement()
+	} else if p.match(token.THROW) {
+		keyword := p.previous()
+		thrown := p.expression()
+		p.consume(token.SEMICOLON, "Expected ';' after thrown value.")
+		return &ast.ThrowStmt{Keyword: keyword, Value: thrown}
+	} else if p.match(token.RETURN) {
+		return p.returnStatement()
+	} else if p.match(token.BREAK) {
+		return &ast.BreakStmt{Token: p.loopControl("break")}
+	} else if p.match(token.CONTINUE) {
+		return &ast.ContinueStmt{Token: p.loopControl("continue")}
+	}
 	return p.expressionStatement()
 }
 
+func (p *Parser) matchStatement() ast.Stmt {
+	keyword := p.previous()
+	p.consume(token.LEFTPAREN, "Expected '(' after 'match'.")
+	subject := p.expression()
+	p.consume(token.RIGHTPAREN, "Expected ')' after match subject.")
+	p.consume(token.LEFTBRACE, "Expected '{' before match cases.")
+
+	cases := make([]ast.MatchCase, 0)
+	for !p.check(token.RIGHTBRACE) && !p.isAtEnd() {
+		p.consume(token.CASE, "Expected 'case' in match body.")
+		cases = append(cases, p.matchCase())
+	}
+
+	p.consume(token.RIGHTBRACE, "Expected '}' after match cases.")
+	return &ast.MatchStmt{Keyword: keyword, Subject: subject, Cases: cases}
+}
+
+func (p *Parser) matchCase() ast.MatchCase {
+	patterns := []ast.Pattern{p.pattern()}
+	for p.match(token.COMMA) {
+		patterns = append(patterns, p.pattern())
+	}
+
+	var guard ast.Expr
+	if p.match(token.IF) {
+		guard = p.guard()
+	}
+
+	p.consume(token.ARROW, "Expected '=>' after case pattern.")
+	body := p.statement()
+	return ast.MatchCase{Patterns: patterns, Guard: guard, Body: body}
+}
+
+// guard parses the condition after `case pattern if`. In `case p if (a) => f();`
+// the '=>' belongs to the case, so arrow functions are only allowed inside brackets.
+func (p *Parser) guard() ast.Expr {
+	enclosing := p.inguard
+	p.inguard = true
+	defer func() { p.inguard = enclosing }()
+
+	return p.pipeline()
+}
+
+// nested is called before parsing between brackets, where '(a) =>' is an
+// arrow function again. The returned func restores the enclosing state.
+func (p *Parser) nested() func() {
+	enclosing := p.inguard
+	p.inguard = false
+	return func() { p.inguard = enclosing }
+}
+
+// pattern parses one of: a literal, `_`, a name to bind, `[p1, p2]`,
+// `{"key": p}` and `Class{field, other: p}`, where a bare field binds a
+// variable of the same name
+func (p *Parser) pattern() ast.Pattern {
+	if p.check(token.NUMBER) || p.check(token.STRING) || p.check(token.TRUE) ||
+		p.check(token.FALSE) || p.check(token.NIL) || p.check(token.MINUS) {
+		tok := p.peek()
+		return &ast.LiteralPattern{Token: tok, Value: p.patternLiteral()}
+	}
+
+	if p.match(token.LEFTBRACKET) {
+		bracket := p.previous()
+		elements := make([]ast.Pattern, 0)
+		for !p.check(token.RIGHTBRACKET) {
+			elements = append(elements, p.pattern())
+			if !p.match(token.COMMA) {
+				break
+			}
+		}
+		p.consume(token.RIGHTBRACKET, "Expected ']' after list pattern.")
+		return &ast.ListPattern{Bracket: bracket, Elements: elements}
+	}
+
+	if p.match(token.LEFTBRACE) {
+		brace := p.previous()
+		keys := make([]value.Value, 0)
+		values := make([]ast.Pattern, 0)
+		for !p.check(token.RIGHTBRACE) {
+			if p.match(token.IDENTIFIER) {
+				keys = append(keys, value.Object(p.previous().Lexeme))
+			} else {
+				keys = append(keys, p.patternLiteral())
+			}
+			p.consume(token.COLON, "Expected ':' after map pattern key.")
+			values = append(values, p.pattern())
+			if !p.match(token.COMMA) {
+				break
+			}
+		}
+		p.consume(token.RIGHTBRACE, "Expected '}' after map pattern.")
+		return &ast.MapPattern{Brace: brace, Keys: keys, Values: values}
+	}
+
+	name := p.consume(token.IDENTIFIER, "Expected pattern.")
+	if name.Lexeme == "_" {
+		return &ast.WildcardPattern{Token: name}
+	}
+	if !p.match(token.LEFTBRACE) {
+		return &ast.BindingPattern{Name: name, EnvIndex: -1, EnvDepth: -1}
+	}
+
+	class := ast.VariableExpr{Name: name, EnvIndex: -1, EnvDepth: -1}
+	fields := make([]token.Token, 0)
+	patterns := make([]ast.Pattern, 0)
+	for !p.check(token.RIGHTBRACE) {
+		field := p.consume(token.IDENTIFIER, "Expected field name in class pattern.")
+		fields = append(fields, field)
+		if p.match(token.COLON) {
+			patterns = append(patterns, p.pattern())
+		} else {
+			patterns = append(patterns, &ast.BindingPattern{Name: field, EnvIndex: -1, EnvDepth: -1})
+		}
+		if !p.match(token.COMMA) {
+			break
+		}
+	}
+	p.consume(token.RIGHTBRACE, "Expected '}' after class pattern.")
+	return &ast.InstancePattern{Class: class, Fields: fields, Patterns: patterns}
+}
+
+// patternLiteral consumes a literal, or a negated number, allowed in a pattern
+func (p *Parser) patternLiteral() value.Value {
+	if p.match(token.TRUE) {
+		return value.Bool(true)
+	} else if p.match(token.FALSE) {
+		return value.Bool(false)
+	} else if p.match(token.NIL) {
+		return value.Nil
+	} else if p.match(token.MINUS) {
+		number := p.consume(token.NUMBER, "Expected number after '-' in pattern.")
+		return value.Number(-number.Literal.AsNumber())
+	}
+	if p.match(token.NUMBER, token.STRING) {
+		return p.previous().Literal
+	}
+	panic(parse_error.FormatByToken(p.peek(), "Expected literal in pattern."))
+}
+
+func (p *Parser) returnStatement() ast.Stmt {
+	keyword := p.previous()
+	var result ast.Expr
+	if !p.check(token.SEMICOLON) {
+		result = p.expression()
+	}
+
+	p.consume(token.SEMICOLON, "Expected ';' after return value.")
+	return &ast.ReturnStmt{Keyword: keyword, Value: result}
+}
+
+// tryStatement parses try/catch/finally. Catch and Finally are nil when the
+// clause is missing, at least one of them has to be there.
+func (p *Parser) tryStatement() ast.Stmt {
+	stmt := &ast.TryStmt{Keyword: p.previous()}
+	p.consume(token.LEFTBRACE, "Expected '{' after 'try'.")
+	stmt.Body = p.block()
+
+	if p.match(token.CATCH) {
+		p.consume(token.LEFTPAREN, "Expected '(' after 'catch'.")
+		stmt.Name = p.consume(token.IDENTIFIER, "Expected error variable name.")
+		p.consume(token.RIGHTPAREN, "Expected ')' after error variable.")
+		p.consume(token.LEFTBRACE, "Expected '{' after catch clause.")
+		stmt.Catch = p.block()
+	}
+	if p.match(token.FINALLY) {
+		p.consume(token.LEFTBRACE, "Expected '{' after 'finally'.")
+		stmt.Finally = p.block()
+	}
+
+	if stmt.Catch == nil && stmt.Finally == nil {
+		panic(parse_error.FormatByToken(p.peek(), "Expected 'catch' or 'finally' after try block."))
+	}
+	return stmt
+}
+
+func (p *Parser) forInStatement() ast.Stmt {
+	keyword := p.previous()
+	p.consume(token.LEFTPAREN, "Expected '(' after 'for'.")
+	p.consume(token.VAR, "Expected 'var' in for-in loop.")
+	name := p.consume(token.IDENTIFIER, "Expected loop variable name.")
+	p.consume(token.IN, "Expected 'in' after loop variable.")
+	iterable := p.expression()
+	p.consume(token.RIGHTPAREN, "Expected ')' after for-in clause.")
+
+	body := p.loopBody()
+	return &ast.ForInStmt{Keyword: keyword, Name: name, Iterable: iterable, Body: body}
+}
+
+func (p *Parser) loopBody() ast.Stmt {
+	enclosing := p.inloop
+	p.inloop = true
+	defer func() { p.inloop = enclosing }()
+
+	return p.statement()
+}
+
+// loopControl finishes a break or continue statement and returns its keyword
+func (p *Parser) loopControl(keyword string) token.Token {
+	tok := p.previous()
+	if !p.inloop {
+		parse_error.ReportAtToken(tok, "Cannot use '"+keyword+"' outside of a loop.")
+	}
+	p.consume(token.SEMICOLON, "Expected ';' after '"+keyword+"'.")
+	return tok
+}
+
+func (p *Parser) block() []ast.Stmt {
+	statements := make([]ast.Stmt, 0)
+
+	for !p.check(token.RIGHTBRACE) && !p.isAtEnd() {
+		statements = append(statements, p.declaration())
+	}
+
+	p.consume(token.RIGHTBRACE, "Expected '}' after block.")
+	return statements
+}
+
 func (p *Parser) expressionStatement() ast.Stmt {
 	expr := p.expression()
 	p.consume(token.SEMICOLON, "Expected ';' after value.")
@@ -65,19 +315,95 @@ func (p *Parser) expression() ast.Expr {
 }
 
 func (p *Parser) comma() ast.Expr {
-	expr := p.assignment()
+	expr := p.pipeline()
 
 	for p.match(",") {
 		operator := p.previous()
-		right := p.assignment()
+		right := p.pipeline()
 		expr = &ast.BinaryExpr{Left: expr, Operator: operator, Right: right}
 	}
 
 	return expr
 }
 
+// pipeline desugars `x |> f` into f(x). If the right side is a call with a
+// single `_` argument, x takes its place instead: `x |> g(1, _)` is g(1, x).
+func (p *Parser) pipeline() ast.Expr {
+	expr := p.assignment()
+
+	for p.match(token.PIPEGREATER) {
+		operator := p.previous()
+		right, placeholders := p.pipeTarget()
+		expr = p.pipe(expr, operator, right, placeholders)
+	}
+
+	return expr
+}
+
+// pipeTarget parses the right side of a |> and the `_`s in it. Nested
+// pipelines take their own, so only the ones left over are returned.
+func (p *Parser) pipeTarget() (ast.Expr, []*ast.VariableExpr) {
+	mark := len(p.placeholders)
+	p.pipelines++
+	defer func() {
+		p.pipelines--
+		p.placeholders = p.placeholders[:mark]
+	}()
+
+	right := p.assignment()
+	return right, append([]*ast.VariableExpr(nil), p.placeholders[mark:]...)
+}
+
+// pipe builds the call for `arg |> callee`. placeholders are the `_`s parsed
+// in callee, only one of them can be used and only as a direct argument.
+func (p *Parser) pipe(arg ast.Expr, operator token.Token, callee ast.Expr, placeholders []*ast.VariableExpr) ast.Expr {
+	target := callee
+	if chain, ok := callee.(*ast.OptionalChainExpr); ok {
+		// `x |> o?.m(_)` substitutes inside the chain, keeping its short-circuit
+		target = chain.Expression
+	}
+
+	replaced := false
+	used := make(map[*ast.VariableExpr]bool)
+	if call, ok := target.(*ast.CallExpr); ok {
+		for i, a := range call.Arguments {
+			v, ok := a.(*ast.VariableExpr)
+			if !ok || !isPlaceholder(v, placeholders) {
+				continue
+			}
+			used[v] = true
+			if replaced {
+				parse_error.ReportAtToken(v.Name, "Cannot have more than one '_' in a pipeline call.")
+				continue
+			}
+			call.Arguments[i] = arg
+			replaced = true
+		}
+	}
+
+	for _, v := range placeholders {
+		if !used[v] {
+			parse_error.ReportAtToken(v.Name, "'_' must be an argument of the call on the right of '|>'.")
+		}
+	}
+
+	if replaced {
+		return callee
+	}
+	return &ast.CallExpr{Callee: callee, Paren: operator, Arguments: []ast.Expr{arg}}
+}
+
+func isPlaceholder(v *ast.VariableExpr, placeholders []*ast.VariableExpr) bool {
+	for _, placeholder := range placeholders {
+		if v == placeholder {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Parser) assignment() ast.Expr {
-	expr := p.or()
+	expr := p.coalesce()
 
 	if p.match(token.EQUAL) {
 		equals := p.previous()
@@ -87,10 +413,40 @@ func (p *Parser) assignment() ast.Expr {
 			return &ast.AssignExpr{Name: variable.Name, Value: value, EnvIndex: -1, EnvDepth: -1}
 		} else if get, ok := expr.(*ast.GetExpr); ok {
 			return &ast.SetExpr{Object: get.Object, Name: get.Name, Value: value}
+		} else if index, ok := expr.(*ast.IndexExpr); ok {
+			return &ast.IndexSetExpr{Object: index.Object, Bracket: index.Bracket, Index: index.Index, Value: value}
 		}
 
 		panic(parse_error.FormatByToken(equals, "Invalid assignment target."))
 	}
+
+	if p.match(token.PLUSEQUAL, token.MINUSEQUAL, token.STAREQUAL, token.SLASHEQUAL, token.PERCENTEQUAL, token.POWEREQUAL) {
+		operator := p.previous()
+		value := p.assignment()
+		p.checkAssignTarget(expr, operator)
+		return &ast.CompoundAssignExpr{Target: expr, Operator: operator, Value: value}
+	}
+	return expr
+}
+
+// checkAssignTarget is for operators that read the target before writing it,
+// the evaluator evaluates the target's object and index only once
+func (p *Parser) checkAssignTarget(expr ast.Expr, operator token.Token) {
+	switch expr.(type) {
+	case *ast.VariableExpr, *ast.GetExpr, *ast.IndexExpr:
+		return
+	}
+	panic(parse_error.FormatByToken(operator, "Invalid assignment target."))
+}
+
+func (p *Parser) coalesce() ast.Expr {
+	expr := p.or()
+
+	for p.match(token.QMARKQMARK) {
+		operator := p.previous()
+		right := p.or()
+		expr = &ast.LogicalExpr{Left: expr, Operator: operator, Right: right}
+	}
 	return expr
 }
 
@@ -116,7 +472,7 @@ func (p *Parser) and() ast.Expr {
 }
 
 func (p *Parser) ternary() ast.Expr {
-	cond := p.equality()
+	cond := p.bitOr()
 	if p.match("?") {
 		qmark := p.previous()
 		thenClause := p.expression()
@@ -128,6 +484,42 @@ func (p *Parser) ternary() ast.Expr {
 	return cond
 }
 
+func (p *Parser) bitOr() ast.Expr {
+	expr := p.bitXor()
+
+	for p.match(token.PIPE) {
+		operator := p.previous()
+		right := p.bitXor()
+		expr = &ast.BinaryExpr{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr
+}
+
+func (p *Parser) bitXor() ast.Expr {
+	expr := p.bitAnd()
+
+	for p.match(token.CARET) {
+		operator := p.previous()
+		right := p.bitAnd()
+		expr = &ast.BinaryExpr{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr
+}
+
+func (p *Parser) bitAnd() ast.Expr {
+	expr := p.equality()
+
+	for p.match(token.AMPERSAND) {
+		operator := p.previous()
+		right := p.equality()
+		expr = &ast.BinaryExpr{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr
+}
+
 func (p *Parser) equality() ast.Expr {
 	expr := p.comparison()
 
@@ -141,9 +533,21 @@ func (p *Parser) equality() ast.Expr {
 }
 
 func (p *Parser) comparison() ast.Expr {
-	expr := p.addition()
+	expr := p.shift()
 
 	for p.match(token.GREATER, token.GREATEREQUAL, token.LESS, token.LESSEQUAL) {
+		operator := p.previous()
+		right := p.shift()
+		expr = &ast.BinaryExpr{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr
+}
+
+func (p *Parser) shift() ast.Expr {
+	expr := p.addition()
+
+	for p.match(token.LESSLESS, token.GREATERGREATER) {
 		operator := p.previous()
 		right := p.addition()
 		expr = &ast.BinaryExpr{Left: expr, Operator: operator, Right: right}
@@ -167,7 +571,7 @@ func (p *Parser) addition() ast.Expr {
 func (p *Parser) multiplication() ast.Expr {
 	expr := p.unary()
 
-	for p.match(token.STAR, token.SLASH) {
+	for p.match(token.STAR, token.SLASH, token.SLASHSLASH, token.PERCENT) {
 		operator := p.previous()
 		right := p.unary()
 		expr = &ast.BinaryExpr{Left: expr, Operator: operator, Right: right}
@@ -177,17 +581,22 @@ func (p *Parser) multiplication() ast.Expr {
 }
 
 func (p *Parser) unary() ast.Expr {
-	if p.match(token.BANG, token.MINUS) {
+	if p.match(token.BANG, token.MINUS, token.TILDE) {
 		operator := p.previous()
 		right := p.unary()
 		return &ast.UnaryExpr{Operator: operator, Right: right}
+	} else if p.match(token.PLUSPLUS, token.MINUSMINUS) {
+		operator := p.previous()
+		target := p.unary()
+		p.checkAssignTarget(target, operator)
+		return &ast.UpdateExpr{Target: target, Operator: operator, Prefix: true}
 	}
 
 	return p.power()
 }
 
 func (p *Parser) power() ast.Expr {
-	expr := p.call()
+	expr := p.postfix()
 
 	for p.match(token.POWER) {
 		operator := p.previous()
@@ -197,8 +606,24 @@ func (p *Parser) power() ast.Expr {
 	return expr
 }
 
+func (p *Parser) postfix() ast.Expr {
+	expr := p.call()
+
+	if p.match(token.PLUSPLUS, token.MINUSMINUS) {
+		operator := p.previous()
+		p.checkAssignTarget(expr, operator)
+		return &ast.UpdateExpr{Target: expr, Operator: operator, Prefix: false}
+	}
+	return expr
+}
+
+// call parses a chain of calls, property accesses and indexes. If the chain
+// has a "?." anywhere, it is wrapped in an OptionalChainExpr: when an optional
+// GetExpr finds nil, evaluation skips the rest of the chain and the whole
+// chain is nil.
 func (p *Parser) call() ast.Expr {
 	expr := p.primary()
+	optional := false
 
 	for {
 		if p.match(token.LEFTPAREN) {
@@ -206,21 +631,34 @@ func (p *Parser) call() ast.Expr {
 		} else if p.match(token.DOT) {
 			name := p.consume(token.IDENTIFIER, "Expected property name after '.'")
 			expr = &ast.GetExpr{Object: expr, Name: name}
+		} else if p.match(token.QMARKDOT) {
+			name := p.consume(token.IDENTIFIER, "Expected property name after '?.'")
+			expr = &ast.GetExpr{Object: expr, Name: name, Optional: true}
+			optional = true
+		} else if p.match(token.LEFTBRACKET) {
+			expr = p.finishIndex(expr)
 		} else {
 			break
 		}
 	}
+
+	if optional {
+		return &ast.OptionalChainExpr{Expression: expr}
+	}
 	return expr
 }
 
 func (p *Parser) finishCall(callee ast.Expr) ast.Expr {
+	defer p.nested()()
+
 	args := make([]ast.Expr, 0)
 	if !p.check(token.RIGHTPAREN) {
 		for {
-			arg := p.assignment() // we don't want the comma operator here
-			if len(args) >= 8 {
-				parse_error.FormatByToken(p.peek(), "Cannot have more than 8 arguments.")
+			if len(args) >= maxArity {
+				// report but keep parsing, the parser isn't confused
+				parse_error.ReportAtToken(p.peek(), fmt.Sprintf("Cannot have more than %v arguments.", maxArity))
 			}
+			arg := p.pipeline() // we don't want the comma operator here
 			args = append(args, arg)
 			if !p.match(token.COMMA) {
 				break
@@ -232,15 +670,104 @@ func (p *Parser) finishCall(callee ast.Expr) ast.Expr {
 	return &ast.CallExpr{Callee: callee, Paren: paren, Arguments: args}
 }
 
+// finishIndex parses "[index]" and the slice forms "[start:end]", "[start:]",
+// "[:end]" and "[:]" after the opening bracket
+func (p *Parser) finishIndex(object ast.Expr) ast.Expr {
+	bracket := p.previous()
+	defer p.nested()()
+
+	var start ast.Expr
+	if !p.check(token.COLON) {
+		start = p.expression()
+		if p.match(token.RIGHTBRACKET) {
+			return &ast.IndexExpr{Object: object, Bracket: bracket, Index: start}
+		}
+	}
+
+	p.consume(token.COLON, "Expected ':' or ']' after index.")
+	var end ast.Expr
+	if !p.check(token.RIGHTBRACKET) {
+		end = p.expression()
+	}
+	p.consume(token.RIGHTBRACKET, "Expected ']' after slice.")
+	return &ast.SliceExpr{Object: object, Bracket: bracket, Start: start, End: end}
+}
+
+func (p *Parser) list() ast.Expr {
+	bracket := p.previous()
+	defer p.nested()()
+	elements := make([]ast.Expr, 0)
+
+	for !p.check(token.RIGHTBRACKET) {
+		elements = append(elements, p.pipeline())
+		if !p.match(token.COMMA) {
+			break
+		}
+	}
+
+	p.consume(token.RIGHTBRACKET, "Expected ']' after list elements.")
+	return &ast.ListExpr{Bracket: bracket, Elements: elements}
+}
+
+// mapLiteral parses `{"a": 1, b: 2}`, a bare identifier key is shorthand for its name as a string
+func (p *Parser) mapLiteral() ast.Expr {
+	brace := p.previous()
+	defer p.nested()()
+	keys := make([]ast.Expr, 0)
+	values := make([]ast.Expr, 0)
+
+	for !p.check(token.RIGHTBRACE) {
+		if p.check(token.IDENTIFIER) && p.checkNext(token.COLON) {
+			keys = append(keys, &ast.LiteralExpr{Value: value.Object(p.advance().Lexeme)})
+		} else {
+			keys = append(keys, p.pipeline())
+		}
+		p.consume(token.COLON, "Expected ':' after map key.")
+		values = append(values, p.pipeline())
+		if !p.match(token.COMMA) {
+			break
+		}
+	}
+
+	p.consume(token.RIGHTBRACE, "Expected '}' after map entries.")
+	return &ast.MapExpr{Brace: brace, Keys: keys, Values: values}
+}
+
+// interpolation parses the tokens of "a ${x} b ${y} c": INTERPOLATION parts
+// each followed by an expression, then the closing STRING part.
+// Empty literal parts are dropped.
+func (p *Parser) interpolation() ast.Expr {
+	defer p.nested()()
+	parts := make([]ast.Expr, 0)
+
+	for {
+		if text := p.previous().Literal; text.AsString() != "" {
+			parts = append(parts, &ast.LiteralExpr{Value: text})
+		}
+		parts = append(parts, p.expression())
+		if !p.match(token.INTERPOLATION) {
+			break
+		}
+	}
+
+	end := p.consume(token.STRING, "Expected '}' after interpolated expression.")
+	if end.Literal.AsString() != "" {
+		parts = append(parts, &ast.LiteralExpr{Value: end.Literal})
+	}
+	return &ast.InterpolationExpr{Parts: parts}
+}
+
 func (p *Parser) primary() ast.Expr {
 	if p.match(token.FALSE) {
-		return &ast.LiteralExpr{Value: false}
+		return &ast.LiteralExpr{Value: value.Bool(false)}
 	} else if p.match(token.TRUE) {
-		return &ast.LiteralExpr{Value: true}
+		return &ast.LiteralExpr{Value: value.Bool(true)}
 	} else if p.match(token.NIL) {
-		return &ast.LiteralExpr{Value: nil}
+		return &ast.LiteralExpr{Value: value.Nil}
 	} else if p.match(token.NUMBER, token.STRING) {
 		return &ast.LiteralExpr{Value: p.previous().Literal}
+	} else if p.match(token.INTERPOLATION) {
+		return p.interpolation()
 	} else if p.match(token.SUPER) {
 		keyword := p.previous()
 		p.consume(token.DOT, "Expected '.' after 'super'.")
@@ -248,16 +775,104 @@ func (p *Parser) primary() ast.Expr {
 		return &ast.SuperExpr{Keyword: keyword, Method: method}
 	} else if p.match(token.THIS) {
 		return &ast.ThisExpr{Keyword: p.previous(), EnvIndex: -1, EnvDepth: -1}
+	} else if p.match(token.FUN) {
+		return p.lambda()
+	} else if p.check(token.LEFTPAREN) && !p.inguard && p.isArrowFunction() {
+		return p.arrowFunction()
 	} else if p.match(token.LEFTPAREN) {
+		defer p.nested()()
 		expr := p.expression()
 		p.consume(token.RIGHTPAREN, "Expected ')' after expression.")
 		return &ast.GroupingExpr{Expression: expr}
+	} else if p.match(token.LEFTBRACKET) {
+		return p.list()
+	} else if p.match(token.LEFTBRACE) {
+		return p.mapLiteral()
 	} else if p.match(token.IDENTIFIER) {
-		return &ast.VariableExpr{Name: p.previous(), EnvIndex: -1, EnvDepth: -1}
+		variable := &ast.VariableExpr{Name: p.previous(), EnvIndex: -1, EnvDepth: -1}
+		if p.pipelines > 0 && variable.Name.Lexeme == "_" {
+			p.placeholders = append(p.placeholders, variable)
+		}
+		return variable
 	}
 	panic(parse_error.FormatByToken(p.peek(), "Expected expression."))
 }
 
+func (p *Parser) lambda() ast.Expr {
+	keyword := p.previous()
+	p.consume(token.LEFTPAREN, "Expected '(' after 'fun'.")
+	params := p.parameters()
+	p.consume(token.LEFTBRACE, "Expected '{' before function body.")
+	body := p.functionBody()
+	return &ast.LambdaExpr{Keyword: keyword, Params: params, Body: body}
+}
+
+// arrowFunction parses "(a, b) => a + b" and "(a, b) => { ... }".
+// An expression body becomes a single return statement.
+func (p *Parser) arrowFunction() ast.Expr {
+	p.consume(token.LEFTPAREN, "Expected '(' before parameters.")
+	params := p.parameters()
+	arrow := p.consume(token.ARROW, "Expected '=>' after parameters.")
+
+	if p.match(token.LEFTBRACE) {
+		return &ast.LambdaExpr{Keyword: arrow, Params: params, Body: p.functionBody()}
+	}
+	expr := p.pipeline()
+	body := []ast.Stmt{&ast.ReturnStmt{Keyword: arrow, Value: expr}}
+	return &ast.LambdaExpr{Keyword: arrow, Params: params, Body: body}
+}
+
+// functionBody parses a block after its '{', outside of any enclosing loop
+// since break and continue can't jump out of a function
+func (p *Parser) functionBody() []ast.Stmt {
+	enclosing := p.inloop
+	p.inloop = false
+	defer func() { p.inloop = enclosing }()
+	defer p.nested()()
+
+	return p.block()
+}
+
+// parameters parses an identifier list up to and including the closing ')'
+func (p *Parser) parameters() []token.Token {
+	params := make([]token.Token, 0)
+	if !p.check(token.RIGHTPAREN) {
+		for {
+			if len(params) >= maxArity {
+				parse_error.ReportAtToken(p.peek(), fmt.Sprintf("Cannot have more than %v parameters.", maxArity))
+			}
+			params = append(params, p.consume(token.IDENTIFIER, "Expected parameter name."))
+			if !p.match(token.COMMA) {
+				break
+			}
+		}
+	}
+	p.consume(token.RIGHTPAREN, "Expected ')' after parameters.")
+	return params
+}
+
+// isArrowFunction looks past the '(' at the current token for "ident, ...) =>"
+// without consuming anything, to tell arrow functions from groupings
+func (p *Parser) isArrowFunction() bool {
+	i := p.current + 1
+	if p.tokens[i].Type != token.RIGHTPAREN {
+		for {
+			if p.tokens[i].Type != token.IDENTIFIER {
+				return false
+			}
+			i++
+			if p.tokens[i].Type != token.COMMA {
+				break
+			}
+			i++
+		}
+		if p.tokens[i].Type != token.RIGHTPAREN {
+			return false
+		}
+	}
+	return p.tokens[i+1].Type == token.ARROW
+}
+
 func (p *Parser) check(tp token.Type) bool {
 	if p.isAtEnd() {
 		return false
@@ -265,6 +880,14 @@ func (p *Parser) check(tp token.Type) bool {
 	return p.peek().Type == tp
 }
 
+// checkNext is check for the token after the current one
+func (p *Parser) checkNext(tp token.Type) bool {
+	if p.isAtEnd() || p.tokens[p.current+1].Type == token.EOF {
+		return false
+	}
+	return p.tokens[p.current+1].Type == tp
+}
+
 func (p *Parser) isAtEnd() bool {
 	return p.peek().Type == token.EOF
 }
@@ -308,7 +931,8 @@ func (p *Parser) synchronize() {
 			return
 		}
 		switch p.peek().Type {
-		case token.CLASS, token.FUN, token.VAR, token.FOR, token.IF, token.WHILE, token.PRINT, token.RETURN:
+		case token.CLASS, token.FUN, token.VAR, token.FOR, token.IF, token.WHILE, token.PRINT, token.RETURN,
+			token.TRY, token.THROW, token.MATCH, token.BREAK, token.CONTINUE:
 			return
 		}
 		p.advance()
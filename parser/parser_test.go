@@ -0,0 +1,262 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/0xsuk/golox/ast"
+	"github.com/0xsuk/golox/parse_error"
+	"github.com/0xsuk/golox/scanner"
+)
+
+// parse parses src and fails the test on any scan or parse error
+func parse(t *testing.T, src string) []ast.Stmt {
+	t.Helper()
+	parse_error.HadError = false
+	sc := scanner.New(src)
+	p := New(sc.ScanTokens())
+	statements := p.Parse()
+	if parse_error.HadError {
+		t.Fatalf("%s: unexpected parse error", src)
+	}
+	return statements
+}
+
+// parseExpr parses src as a single expression statement
+func parseExpr(t *testing.T, src string) ast.Expr {
+	t.Helper()
+	statements := parse(t, src+";")
+	if len(statements) != 1 {
+		t.Fatalf("%s: got %v statements", src, len(statements))
+	}
+	return statements[0].(*ast.ExpressionStmt).Expression
+}
+
+// parseFails reports whether src has a scan or parse error
+func parseFails(src string) bool {
+	parse_error.HadError = false
+	sc := scanner.New(src)
+	p := New(sc.ScanTokens())
+	p.Parse()
+	failed := parse_error.HadError
+	parse_error.HadError = false
+	return failed
+}
+
+func TestLambdaReturn(t *testing.T) {
+	lambda := parseExpr(t, "fun (a) { return a; }").(*ast.LambdaExpr)
+	ret, ok := lambda.Body[0].(*ast.ReturnStmt)
+	if !ok || ret.Value == nil {
+		t.Fatalf("body = %#v, want a return with a value", lambda.Body[0])
+	}
+
+	lambda = parseExpr(t, "(a) => { return; }").(*ast.LambdaExpr)
+	if ret := lambda.Body[0].(*ast.ReturnStmt); ret.Value != nil {
+		t.Errorf("bare return has value %#v", ret.Value)
+	}
+}
+
+func TestSynchronizeAtNewStatements(t *testing.T) {
+	tests := []struct {
+		src  string
+		want ast.Stmt
+	}{
+		{"f(1 2 try { a; } catch (e) { b; }", &ast.TryStmt{}},
+		{"f(1 2 throw e;", &ast.ThrowStmt{}},
+		{"f(1 2 match (v) { case _ => a; }", &ast.MatchStmt{}},
+	}
+
+	for _, tt := range tests {
+		parse_error.HadError = false
+		sc := scanner.New(tt.src)
+		p := New(sc.ScanTokens())
+		statements := p.Parse()
+		parse_error.HadError = false
+
+		if len(statements) != 2 || statements[0] != nil {
+			t.Errorf("%s: got %#v, want the error then one statement", tt.src, statements)
+			continue
+		}
+		if got, want := fmt.Sprintf("%T", statements[1]), fmt.Sprintf("%T", tt.want); got != want {
+			t.Errorf("%s: recovered into %s, want %s", tt.src, got, want)
+		}
+	}
+}
+
+func TestInterpolationParts(t *testing.T) {
+	interpolation := parseExpr(t, `"a ${x} b ${"c"}"`).(*ast.InterpolationExpr)
+
+	want := []string{"*ast.LiteralExpr", "*ast.VariableExpr", "*ast.LiteralExpr", "*ast.LiteralExpr"}
+	if len(interpolation.Parts) != len(want) {
+		t.Fatalf("got %v parts, want %v", len(interpolation.Parts), len(want))
+	}
+	for i, part := range interpolation.Parts {
+		if got := fmt.Sprintf("%T", part); got != want[i] {
+			t.Errorf("part %v is %s, want %s", i, got, want[i])
+		}
+	}
+}
+
+func TestIndexAndSlice(t *testing.T) {
+	tests := []struct {
+		src        string
+		start, end bool // whether the slice has each bound
+	}{
+		{"xs[1:2]", true, true},
+		{"xs[1:]", true, false},
+		{"xs[:-1]", false, true},
+		{"xs[:]", false, false},
+	}
+
+	for _, tt := range tests {
+		slice, ok := parseExpr(t, tt.src).(*ast.SliceExpr)
+		if !ok {
+			t.Errorf("%s: not a slice", tt.src)
+			continue
+		}
+		if (slice.Start != nil) != tt.start || (slice.End != nil) != tt.end {
+			t.Errorf("%s: start %v end %v", tt.src, slice.Start, slice.End)
+		}
+	}
+
+	if _, ok := parseExpr(t, "xs[c ? 1 : 2]").(*ast.IndexExpr); !ok {
+		t.Errorf("a ternary index should not parse as a slice")
+	}
+	if _, ok := parseExpr(t, "xs[i] = 1").(*ast.IndexSetExpr); !ok {
+		t.Errorf("an index assignment should parse as IndexSetExpr")
+	}
+	if !parseFails("xs[1:2] = 1;") {
+		t.Errorf("a slice is not an assignment target")
+	}
+}
+
+func TestOptionalChain(t *testing.T) {
+	chain, ok := parseExpr(t, "a?.b.c()").(*ast.OptionalChainExpr)
+	if !ok {
+		t.Fatalf("a?.b.c() is not wrapped in an OptionalChainExpr")
+	}
+	call := chain.Expression.(*ast.CallExpr)
+	get := call.Callee.(*ast.GetExpr)
+	if get.Optional || !get.Object.(*ast.GetExpr).Optional {
+		t.Errorf("only the ?. access should be optional")
+	}
+
+	// a grouping ends the chain
+	if _, ok := parseExpr(t, "(a?.b).c").(*ast.GetExpr); !ok {
+		t.Errorf("(a?.b).c should be a plain GetExpr")
+	}
+	if _, ok := parseExpr(t, "a ?? b").(*ast.LogicalExpr); !ok {
+		t.Errorf("a ?? b should be a LogicalExpr")
+	}
+	if _, ok := parseExpr(t, "a ? b : c").(*ast.TernaryExpr); !ok {
+		t.Errorf("a ? b : c should still be a ternary")
+	}
+	if !parseFails("a?.b = 1;") {
+		t.Errorf("an optional chain is not an assignment target")
+	}
+}
+
+func TestPipelinePlaceholder(t *testing.T) {
+	call := parseExpr(t, "v |> g(1, _)").(*ast.CallExpr)
+	if call.Callee.(*ast.VariableExpr).Name.Lexeme != "g" || len(call.Arguments) != 2 {
+		t.Fatalf("got %#v, want g(1, v)", call)
+	}
+	if arg := call.Arguments[1].(*ast.VariableExpr); arg.Name.Lexeme != "v" {
+		t.Errorf("placeholder replaced by %s, want v", arg.Name.Lexeme)
+	}
+
+	call = parseExpr(t, "v |> f").(*ast.CallExpr)
+	if call.Arguments[0].(*ast.VariableExpr).Name.Lexeme != "v" {
+		t.Errorf("v |> f should call f(v)")
+	}
+
+	if !parseFails("v |> g(_, _);") {
+		t.Errorf("two placeholders in one call should be an error")
+	}
+
+	// the placeholder of a call inside a ?. chain
+	chain, ok := parseExpr(t, "v |> o?.m(1, _)").(*ast.OptionalChainExpr)
+	if !ok {
+		t.Fatalf("v |> o?.m(1, _) should stay an optional chain")
+	}
+	if arg := chain.Expression.(*ast.CallExpr).Arguments[1].(*ast.VariableExpr); arg.Name.Lexeme != "v" {
+		t.Errorf("placeholder in an optional chain replaced by %s, want v", arg.Name.Lexeme)
+	}
+
+	// nested pipelines each use their own placeholder
+	call = parseExpr(t, "v |> g(w |> h(_), _)").(*ast.CallExpr)
+	if arg := call.Arguments[1].(*ast.VariableExpr); arg.Name.Lexeme != "v" {
+		t.Errorf("outer placeholder replaced by %s, want v", arg.Name.Lexeme)
+	}
+
+	// `_` outside a pipeline is an ordinary variable
+	parseExpr(t, "f(_)")
+
+	for _, src := range []string{"v |> (g(_));", "v |> g(h(_));", "v |> _;", "v |> g(_ |> h, 1);"} {
+		if !parseFails(src) {
+			t.Errorf("%s: an unreplaced '_' should be an error", src)
+		}
+	}
+}
+
+func TestPipelineRecovery(t *testing.T) {
+	// a parse error on the right of |> must not leave later `_`s counted as placeholders
+	parse_error.HadError = false
+	sc := scanner.New("v |> g(1 2; f(_);")
+	p := New(sc.ScanTokens())
+	p.Parse()
+	parse_error.HadError = false
+
+	if p.pipelines != 0 || len(p.placeholders) != 0 {
+		t.Errorf("after recovery pipelines = %v, placeholders = %v", p.pipelines, len(p.placeholders))
+	}
+}
+
+func TestArrowBodyPipeline(t *testing.T) {
+	// the body of an arrow function takes the whole pipeline
+	lambda := parseExpr(t, "(x) => x |> f").(*ast.LambdaExpr)
+	call, ok := lambda.Body[0].(*ast.ReturnStmt).Value.(*ast.CallExpr)
+	if !ok || call.Callee.(*ast.VariableExpr).Name.Lexeme != "f" {
+		t.Fatalf("body returns %#v, want f(x)", lambda.Body[0].(*ast.ReturnStmt).Value)
+	}
+
+	call = parseExpr(t, "xs |> map(_, (x) => x |> double)").(*ast.CallExpr)
+	if arg := call.Arguments[0].(*ast.VariableExpr); arg.Name.Lexeme != "xs" {
+		t.Errorf("placeholder replaced by %s, want xs", arg.Name.Lexeme)
+	}
+	if _, ok := call.Arguments[1].(*ast.LambdaExpr); !ok {
+		t.Errorf("second argument is %T, want the arrow function", call.Arguments[1])
+	}
+}
+
+func TestMatchGuard(t *testing.T) {
+	tests := []struct {
+		src   string
+		guard ast.Expr
+	}{
+		{"match (v) { case p if a => f(); }", &ast.VariableExpr{}},
+		{"match (v) { case p if (a) => f(); }", &ast.GroupingExpr{}},
+		{"match (v) { case p if (a, b) => f(); }", &ast.GroupingExpr{}},
+		// arrow functions are still allowed inside brackets
+		{"match (v) { case p if all(xs, (x) => x > 0) => f(); }", &ast.CallExpr{}},
+		{"match (v) { case p if ((x) => x)(a) => f(); }", &ast.CallExpr{}},
+	}
+
+	for _, tt := range tests {
+		statements := parse(t, tt.src)
+		c := statements[0].(*ast.MatchStmt).Cases[0]
+		if fmt.Sprintf("%T", c.Guard) != fmt.Sprintf("%T", tt.guard) {
+			t.Errorf("%s: guard is %T, want %T", tt.src, c.Guard, tt.guard)
+		}
+		if _, ok := c.Body.(*ast.ExpressionStmt); !ok {
+			t.Errorf("%s: body is %T, want the call statement", tt.src, c.Body)
+		}
+	}
+
+	// an arrow function in a later expression statement is unaffected
+	statements := parse(t, "match (v) { case p if (a) => f(); } g((x) => x);")
+	call := statements[1].(*ast.ExpressionStmt).Expression.(*ast.CallExpr)
+	if _, ok := call.Arguments[0].(*ast.LambdaExpr); !ok {
+		t.Errorf("arrow function after a guard parsed as %T", call.Arguments[0])
+	}
+}
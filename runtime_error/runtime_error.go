@@ -3,11 +3,62 @@ package runtime_error
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
 var HadError = false
 
+// A trace longer than TraceHead+TraceTail frames prints only its innermost
+// TraceHead and outermost TraceTail frames, so runaway recursion (direct or
+// mutual) doesn't flood stderr
+const (
+	TraceHead = 10
+	TraceTail = 5
+)
+
+// Frame is one call in a stack trace. Function is the callee name, qualified
+// by its class for methods ("Point.init"), and Line is the line of the call site.
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+func (f Frame) String() string {
+	if f.File == "" {
+		return fmt.Sprintf("at %s (line %v)", f.Function, f.Line)
+	}
+	return fmt.Sprintf("at %s (%s:%v)", f.Function, f.File, f.Line)
+}
+
 func ReportAtLine(line int, message string) {
 	fmt.Fprintf(os.Stderr, "[line %v] Error :%s\n", line, message)
 	HadError = true
 }
+
+// ReportWithTrace reports like ReportAtLine followed by the stack trace,
+// frames ordered innermost first
+func ReportWithTrace(line int, message string, frames []Frame) {
+	fmt.Fprintf(os.Stderr, "[line %v] Error :%s\n%s", line, message, FormatTrace(frames))
+	HadError = true
+}
+
+func FormatTrace(frames []Frame) string {
+	var sb strings.Builder
+
+	if len(frames) <= TraceHead+TraceTail {
+		writeFrames(&sb, frames)
+		return sb.String()
+	}
+
+	writeFrames(&sb, frames[:TraceHead])
+	fmt.Fprintf(&sb, "    ... %v frames omitted ...\n", len(frames)-TraceHead-TraceTail)
+	writeFrames(&sb, frames[len(frames)-TraceTail:])
+	return sb.String()
+}
+
+func writeFrames(sb *strings.Builder, frames []Frame) {
+	for _, frame := range frames {
+		sb.WriteString("    " + frame.String() + "\n")
+	}
+}
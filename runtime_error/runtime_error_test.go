@@ -0,0 +1,58 @@
+package runtime_error
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatTraceShort(t *testing.T) {
+	frames := []Frame{
+		{Function: "inner", File: "a.lox", Line: 3},
+		{Function: "Point.init", Line: 7},
+	}
+
+	got := FormatTrace(frames)
+	want := "    at inner (a.lox:3)\n    at Point.init (line 7)\n"
+	if got != want {
+		t.Errorf("FormatTrace() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatTraceCapsMutualRecursion(t *testing.T) {
+	frames := make([]Frame, 0)
+	for i := 0; i < 1000; i++ {
+		// a -> b -> a -> ... called from alternating lines
+		if i%2 == 0 {
+			frames = append(frames, Frame{Function: "a", Line: 2})
+		} else {
+			frames = append(frames, Frame{Function: "b", Line: 5})
+		}
+	}
+	frames = append(frames, Frame{Function: "script", Line: 9})
+
+	lines := strings.Split(strings.TrimSuffix(FormatTrace(frames), "\n"), "\n")
+	if len(lines) != TraceHead+1+TraceTail {
+		t.Fatalf("got %v lines, want %v", len(lines), TraceHead+1+TraceTail)
+	}
+	if lines[TraceHead] != "    ... 986 frames omitted ..." {
+		t.Errorf("omitted line = %q", lines[TraceHead])
+	}
+	if lines[0] != "    at a (line 2)" {
+		t.Errorf("innermost frame = %q", lines[0])
+	}
+	if last := lines[len(lines)-1]; last != "    at script (line 9)" {
+		t.Errorf("outermost frame = %q", last)
+	}
+}
+
+func TestFormatTraceAtLimit(t *testing.T) {
+	frames := make([]Frame, TraceHead+TraceTail)
+	for i := range frames {
+		frames[i] = Frame{Function: "f", Line: 1}
+	}
+
+	got := strings.Count(FormatTrace(frames), "\n")
+	if got != TraceHead+TraceTail {
+		t.Errorf("got %v lines, want every frame printed", got)
+	}
+}
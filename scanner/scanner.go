@@ -2,9 +2,11 @@ package scanner
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/0xsuk/golox/parse_error"
 	"github.com/0xsuk/golox/token"
+	"github.com/0xsuk/golox/value"
 )
 
 var keywords = map[string]token.Type{
@@ -15,6 +17,7 @@ var keywords = map[string]token.Type{
 	"for":      token.FOR,
 	"fun":      token.FUN,
 	"if":       token.IF,
+	"in":       token.IN,
 	"nil":      token.NIL,
 	"or":       token.OR,
 	"print":    token.PRINT,
@@ -26,6 +29,12 @@ var keywords = map[string]token.Type{
 	"while":    token.WHILE,
 	"break":    token.BREAK,
 	"continue": token.CONTINUE,
+	"try":      token.TRY,
+	"catch":    token.CATCH,
+	"finally":  token.FINALLY,
+	"throw":    token.THROW,
+	"match":    token.MATCH,
+	"case":     token.CASE,
 }
 
 type Scanner struct {
@@ -34,6 +43,8 @@ type Scanner struct {
 	current int
 	line    int
 	tokens  []token.Token
+	// open braces inside each enclosing "${ }", innermost last
+	interpolations []int
 }
 
 func New(source string) Scanner {
@@ -46,6 +57,9 @@ func (sc *Scanner) ScanTokens() []token.Token {
 		sc.start = sc.current
 		sc.scanToken()
 	}
+	if len(sc.interpolations) > 0 {
+		parse_error.ReportAtLine(sc.line, "Unterminated string interpolation.")
+	}
 	sc.tokens = append(sc.tokens, token.Token{Type: token.EOF})
 	return sc.tokens
 }
@@ -77,26 +91,69 @@ func (sc *Scanner) scanToken() {
 	case ')':
 		sc.addToken(token.RIGHTPAREN)
 	case '{':
+		if n := len(sc.interpolations); n > 0 {
+			sc.interpolations[n-1]++
+		}
 		sc.addToken(token.LEFTBRACE)
 	case '}':
+		if n := len(sc.interpolations); n > 0 {
+			if sc.interpolations[n-1] == 0 {
+				// end of "${ }", the rest is more of the string
+				sc.interpolations = sc.interpolations[:n-1]
+				if sc.tokens[len(sc.tokens)-1].Type == token.INTERPOLATION {
+					parse_error.ReportAtLine(sc.line, "Expected expression in string interpolation.")
+				}
+				sc.scanString()
+				return
+			}
+			sc.interpolations[n-1]--
+		}
 		sc.addToken(token.RIGHTBRACE)
+	case '[':
+		sc.addToken(token.LEFTBRACKET)
+	case ']':
+		sc.addToken(token.RIGHTBRACKET)
 	case ',':
 		sc.addToken(token.COMMA)
 	case '.':
 		sc.addToken(token.DOT)
 	case '-':
-		sc.addToken(token.MINUS)
+		if sc.match('-') {
+			sc.addToken(token.MINUSMINUS)
+		} else if sc.match('=') {
+			sc.addToken(token.MINUSEQUAL)
+		} else {
+			sc.addToken(token.MINUS)
+		}
 	case '+':
-		sc.addToken(token.PLUS)
+		if sc.match('+') {
+			sc.addToken(token.PLUSPLUS)
+		} else if sc.match('=') {
+			sc.addToken(token.PLUSEQUAL)
+		} else {
+			sc.addToken(token.PLUS)
+		}
 	case '?':
-		sc.addToken(token.QMARK)
+		if sc.match('.') {
+			sc.addToken(token.QMARKDOT)
+		} else if sc.match('?') {
+			sc.addToken(token.QMARKQMARK)
+		} else {
+			sc.addToken(token.QMARK)
+		}
 	case ':':
 		sc.addToken(token.COLON)
 	case ';':
 		sc.addToken(token.SEMICOLON)
 	case '*':
 		if sc.match('*') {
-			sc.addToken(token.POWER)
+			if sc.match('=') {
+				sc.addToken(token.POWEREQUAL)
+			} else {
+				sc.addToken(token.POWER)
+			}
+		} else if sc.match('=') {
+			sc.addToken(token.STAREQUAL)
 		} else {
 			sc.addToken(token.STAR)
 		}
@@ -109,30 +166,58 @@ func (sc *Scanner) scanToken() {
 	case '=':
 		if sc.match('=') {
 			sc.addToken(token.EQUALEQUAL)
+		} else if sc.match('>') {
+			sc.addToken(token.ARROW)
 		} else {
 			sc.addToken(token.EQUAL)
 		}
 	case '<':
 		if sc.match('=') {
 			sc.addToken(token.LESSEQUAL)
+		} else if sc.match('<') {
+			sc.addToken(token.LESSLESS)
 		} else {
 			sc.addToken(token.LESS)
 		}
 	case '>':
 		if sc.match('=') {
 			sc.addToken(token.GREATEREQUAL)
+		} else if sc.match('>') {
+			sc.addToken(token.GREATERGREATER)
 		} else {
 			sc.addToken(token.GREATER)
 		}
 	case '/':
-		if sc.match('/') {
+		if sc.peek() == '/' && !sc.followsOperand() {
 			// A comment goes until the end of the line
 			for sc.peek() != '\n' && !sc.isAtEnd() {
 				sc.advance()
 			}
+		} else if sc.match('/') {
+			sc.addToken(token.SLASHSLASH)
+		} else if sc.match('=') {
+			sc.addToken(token.SLASHEQUAL)
 		} else {
 			sc.addToken(token.SLASH)
 		}
+	case '%':
+		if sc.match('=') {
+			sc.addToken(token.PERCENTEQUAL)
+		} else {
+			sc.addToken(token.PERCENT)
+		}
+	case '&':
+		sc.addToken(token.AMPERSAND)
+	case '|':
+		if sc.match('>') {
+			sc.addToken(token.PIPEGREATER)
+		} else {
+			sc.addToken(token.PIPE)
+		}
+	case '^':
+		sc.addToken(token.CARET)
+	case '~':
+		sc.addToken(token.TILDE)
 	case '\n':
 		sc.line++
 	case ' ', '\r', '\t':
@@ -150,8 +235,26 @@ func (sc *Scanner) scanToken() {
 	}
 }
 
+// scanString scans from just after an opening '"' or the '}' closing an
+// interpolation. A "${" ends the token as an INTERPOLATION and the embedded
+// expression is scanned as ordinary tokens until its matching '}'.
+// "\${" is a literal "${". No other backslash sequence is special.
 func (sc *Scanner) scanString() {
-	for sc.peek() == '"' && !sc.isAtEnd() {
+	for sc.peek() != '"' && !sc.isAtEnd() {
+		if sc.peek() == '\\' && sc.peekNext() == '$' {
+			// \${ is a literal ${, skip the $ so it doesn't start an interpolation
+			sc.advance()
+			sc.advance()
+			continue
+		}
+		if sc.peek() == '$' && sc.peekNext() == '{' {
+			text := unescape(sc.source[sc.start+1 : sc.current])
+			sc.advance()
+			sc.advance()
+			sc.addTokenWithLiteral(token.INTERPOLATION, value.Object(text))
+			sc.interpolations = append(sc.interpolations, 0)
+			return
+		}
 		if sc.peek() == '\n' {
 			sc.line++
 		}
@@ -166,8 +269,13 @@ func (sc *Scanner) scanString() {
 	// the closing "
 	sc.advance()
 
-	value := sc.source[sc.start+1 : sc.current-1]
-	sc.addTokenWithLiteral(token.STRING, value)
+	text := unescape(sc.source[sc.start+1 : sc.current-1])
+	sc.addTokenWithLiteral(token.STRING, value.Object(text))
+}
+
+// unescape turns each \${ in a string part into a literal ${
+func unescape(text string) string {
+	return strings.ReplaceAll(text, `\${`, "${")
 }
 
 func (sc *Scanner) scanNumber() {
@@ -189,7 +297,7 @@ func (sc *Scanner) scanNumber() {
 		return
 	}
 
-	sc.addTokenWithLiteral(token.NUMBER, number)
+	sc.addTokenWithLiteral(token.NUMBER, value.Number(number))
 }
 
 func (sc *Scanner) scanIdentifier() {
@@ -206,6 +314,24 @@ func (sc *Scanner) scanIdentifier() {
 	}
 }
 
+// followsOperand tells "a // b" (floor division) from a "// comment": it is
+// division only right after something that ends an operand on the same line
+func (sc *Scanner) followsOperand() bool {
+	if len(sc.tokens) == 0 {
+		return false
+	}
+	prev := sc.tokens[len(sc.tokens)-1]
+	if prev.Line != sc.line {
+		return false
+	}
+	switch prev.Type {
+	case token.NUMBER, token.STRING, token.IDENTIFIER, token.RIGHTPAREN, token.RIGHTBRACKET,
+		token.TRUE, token.FALSE, token.NIL, token.THIS:
+		return true
+	}
+	return false
+}
+
 //advance advances current, return previous char
 func (sc *Scanner) advance() byte {
 	sc.current++
@@ -213,10 +339,10 @@ func (sc *Scanner) advance() byte {
 }
 
 func (sc *Scanner) addToken(tp token.Type) {
-	sc.addTokenWithLiteral(tp, nil)
+	sc.addTokenWithLiteral(tp, value.Nil)
 }
 
-func (sc *Scanner) addTokenWithLiteral(tp token.Type, literal interface{}) {
+func (sc *Scanner) addTokenWithLiteral(tp token.Type, literal value.Value) {
 	text := sc.source[sc.start:sc.current]
 	sc.tokens = append(sc.tokens, token.Token{Type: tp, Lexeme: text, Literal: literal, Line: sc.line})
 }
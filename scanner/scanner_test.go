@@ -0,0 +1,162 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/0xsuk/golox/parse_error"
+	"github.com/0xsuk/golox/token"
+)
+
+// scan returns the tokens of src without the trailing EOF and whether
+// scanning reported an error
+func scan(src string) ([]token.Token, bool) {
+	parse_error.HadError = false
+	sc := New(src)
+	tokens := sc.ScanTokens()
+	failed := parse_error.HadError
+	parse_error.HadError = false
+	return tokens[:len(tokens)-1], failed
+}
+
+func types(tokens []token.Token) []token.Type {
+	types := make([]token.Type, len(tokens))
+	for i, tok := range tokens {
+		types[i] = tok.Type
+	}
+	return types
+}
+
+func TestInterpolation(t *testing.T) {
+	tests := []struct {
+		src   string
+		types []token.Type
+		texts []string // literal of every string part, in order
+	}{
+		{
+			`"Hello ${name}, you are ${age + 1}"`,
+			[]token.Type{token.INTERPOLATION, token.IDENTIFIER, token.INTERPOLATION, token.IDENTIFIER, token.PLUS, token.NUMBER, token.STRING},
+			[]string{"Hello ", ", you are ", ""},
+		},
+		{
+			// a string nested inside an interpolation, itself interpolating
+			`"a ${"b ${c} d"} e"`,
+			[]token.Type{token.INTERPOLATION, token.INTERPOLATION, token.IDENTIFIER, token.STRING, token.STRING},
+			[]string{"a ", "b ", " d", " e"},
+		},
+		{
+			// the map literal's braces don't end the interpolation
+			`"${ {"k": {}}["k"] }!"`,
+			[]token.Type{token.INTERPOLATION, token.LEFTBRACE, token.STRING, token.COLON, token.LEFTBRACE, token.RIGHTBRACE,
+				token.RIGHTBRACE, token.LEFTBRACKET, token.STRING, token.RIGHTBRACKET, token.STRING},
+			[]string{"", "k", "k", "!"},
+		},
+		{
+			// an escaped ${ is part of the string
+			`"cost: \${" + x`,
+			[]token.Type{token.STRING, token.PLUS, token.IDENTIFIER},
+			[]string{"cost: ${"},
+		},
+		{
+			`"\${a} is ${a}"`,
+			[]token.Type{token.INTERPOLATION, token.IDENTIFIER, token.STRING},
+			[]string{"${a} is ", ""},
+		},
+		{
+			`"no $ {interpolation} here"`,
+			[]token.Type{token.STRING},
+			[]string{"no $ {interpolation} here"},
+		},
+	}
+
+	for _, tt := range tests {
+		tokens, failed := scan(tt.src)
+		if failed {
+			t.Errorf("%s: unexpected error", tt.src)
+			continue
+		}
+		if got := types(tokens); !reflect.DeepEqual(got, tt.types) {
+			t.Errorf("%s: got %v, want %v", tt.src, got, tt.types)
+			continue
+		}
+
+		texts := make([]string, 0)
+		for _, tok := range tokens {
+			if tok.Type == token.STRING || tok.Type == token.INTERPOLATION {
+				texts = append(texts, tok.Literal.AsString())
+			}
+		}
+		if !reflect.DeepEqual(texts, tt.texts) {
+			t.Errorf("%s: string parts %q, want %q", tt.src, texts, tt.texts)
+		}
+	}
+}
+
+func TestInterpolationErrors(t *testing.T) {
+	for _, src := range []string{`"${}"`, `"a ${ }"`, `"x ${`, `"x ${y`, `"x ${ {y }"`} {
+		if _, failed := scan(src); !failed {
+			t.Errorf("%s: expected a scan error", src)
+		}
+	}
+}
+
+func TestInterpolationBracesOutside(t *testing.T) {
+	// braces around a string are counted only while inside an interpolation
+	tokens, failed := scan(`{ "${a}" }`)
+	want := []token.Type{token.LEFTBRACE, token.INTERPOLATION, token.IDENTIFIER, token.STRING, token.RIGHTBRACE}
+	if failed || !reflect.DeepEqual(types(tokens), want) {
+		t.Errorf("got %v (error %v), want %v", types(tokens), failed, want)
+	}
+}
+
+func TestSlashSlash(t *testing.T) {
+	tests := []struct {
+		src      string
+		division bool
+	}{
+		// floor division after anything that ends an operand
+		{"7 // 2", true},
+		{"a // b", true},
+		{`"s" // 2`, true},
+		{"f(x) // 2", true},
+		{"xs[0] // 2", true},
+		{"this // 2", true},
+		{"nil // 2", true},
+		// the trailing ')' and name cases documented in README.md
+		{"if (x) // comment", true},
+		{"catch (e) // comment", true},
+		{"class A // comment", true},
+		{"class B < A // comment", true},
+		{"fun f(a) // comment", true},
+		// a comment anywhere else
+		{"// comment", false},
+		{"a = 1; // comment", false},
+		{"{ // comment", false},
+		{"} // comment", false},
+		{"f(a, // comment", false},
+		{"a = // comment", false},
+		{"return // comment", false},
+		{"a\n// comment", false},
+	}
+
+	for _, tt := range tests {
+		tokens, _ := scan(tt.src)
+		division := false
+		for _, tok := range tokens {
+			if tok.Type == token.SLASHSLASH {
+				division = true
+			}
+		}
+		if division != tt.division {
+			t.Errorf("%q: division %v, want %v", tt.src, division, tt.division)
+		}
+		if !division {
+			// the comment swallows the rest of its line
+			for _, tok := range tokens {
+				if tok.Lexeme == "comment" {
+					t.Errorf("%q: comment text was scanned as tokens", tt.src)
+				}
+			}
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package token
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/0xsuk/golox/value"
+)
 
 // Type is the type of the token given as a string
 type Type string
@@ -8,33 +12,58 @@ type Type string
 //
 const (
 	// single-character tokens
-	LEFTPAREN  = "("
-	RIGHTPAREN = ")"
-	LEFTBRACE  = "{"
-	RIGHTBRACE = "}"
-	COMMA      = ","
-	DOT        = "."
-	MINUS      = "-"
-	PLUS       = "+"
-	SEMICOLON  = ";"
-	SLASH      = "/"
-	STAR       = "*"
-	QMARK      = "?"
-	COLON      = ":"
+	LEFTPAREN    = "("
+	RIGHTPAREN   = ")"
+	LEFTBRACE    = "{"
+	RIGHTBRACE   = "}"
+	LEFTBRACKET  = "["
+	RIGHTBRACKET = "]"
+	COMMA        = ","
+	DOT          = "."
+	MINUS        = "-"
+	PLUS         = "+"
+	SEMICOLON    = ";"
+	SLASH        = "/"
+	STAR         = "*"
+	QMARK        = "?"
+	COLON        = ":"
+	PERCENT      = "%"
+	AMPERSAND    = "&"
+	PIPE         = "|"
+	CARET        = "^"
+	TILDE        = "~"
 	// one or two character tokens
-	BANG         = "!"
-	BANGEQUAL    = "!="
-	EQUAL        = "="
-	EQUALEQUAL   = "=="
-	GREATER      = ">"
-	GREATEREQUAL = ">="
-	LESS         = "<"
-	LESSEQUAL    = "<="
-	POWER        = "**"
+	BANG           = "!"
+	BANGEQUAL      = "!="
+	EQUAL          = "="
+	EQUALEQUAL     = "=="
+	GREATER        = ">"
+	GREATEREQUAL   = ">="
+	LESS           = "<"
+	LESSEQUAL      = "<="
+	POWER          = "**"
+	ARROW          = "=>"
+	SLASHSLASH     = "//"
+	LESSLESS       = "<<"
+	GREATERGREATER = ">>"
+	PLUSPLUS       = "++"
+	MINUSMINUS     = "--"
+	QMARKDOT       = "?."
+	QMARKQMARK     = "??"
+	PIPEGREATER    = "|>"
+	// compound assignment
+	PLUSEQUAL    = "+="
+	MINUSEQUAL   = "-="
+	STAREQUAL    = "*="
+	SLASHEQUAL   = "/="
+	PERCENTEQUAL = "%="
+	POWEREQUAL   = "**="
 	// literals
 	IDENTIFIER = "IDENT"
 	STRING     = "STRING"
 	NUMBER     = "NUMBER"
+	// a string literal part ending in "${", see scanner.scanString
+	INTERPOLATION = "INTERPOLATION"
 	// keywords
 	AND      = "and"
 	CLASS    = "class"
@@ -43,6 +72,7 @@ const (
 	FUN      = "fun"
 	FOR      = "for"
 	IF       = "if"
+	IN       = "in"
 	NIL      = "nil"
 	OR       = "or"
 	PRINT    = "print"
@@ -54,6 +84,12 @@ const (
 	WHILE    = "while"
 	BREAK    = "break"
 	CONTINUE = "continue"
+	TRY      = "try"
+	CATCH    = "catch"
+	FINALLY  = "finally"
+	THROW    = "throw"
+	MATCH    = "match"
+	CASE     = "case"
 	EOF      = "eof"
 	INVALID  = "__INVALID__"
 )
@@ -62,7 +98,7 @@ const (
 type Token struct {
 	Type    Type
 	Lexeme  string
-	Literal interface{}
+	Literal value.Value
 	Line    int
 }
 
@@ -8,40 +8,65 @@ import (
 func main() {
 	exprNodes := []string{
 		"Assign   : Name token.Token, Value Expr, EnvIndex int, EnvDepth int",
+		"CompoundAssign : Target Expr, Operator token.Token, Value Expr",
 		"Binary   : Left Expr, Operator token.Token, Right Expr",
 		"Ternary  : Condition Expr, QMark token.Token, Then Expr, Colon token.Token, Else Expr",
 		"Call     : Callee Expr, Paren token.Token, Arguments []Expr",
-		"Get      : Object Expr, Name token.Token",
+		"Get      : Object Expr, Name token.Token, Optional bool",
 		"Grouping : Expression Expr",
-		"Literal  : Value interface{}",
+		"Interpolation : Parts []Expr",
+		"Index    : Object Expr, Bracket token.Token, Index Expr",
+		"IndexSet : Object Expr, Bracket token.Token, Index Expr, Value Expr",
+		"Lambda   : Keyword token.Token, Params []token.Token, Body []Stmt",
+		"List     : Bracket token.Token, Elements []Expr",
+		"Literal  : Value value.Value",
 		"Logical  : Left Expr, Operator token.Token, Right Expr",
+		"Map      : Brace token.Token, Keys []Expr, Values []Expr",
+		"OptionalChain : Expression Expr",
 		"Set      : Object Expr, Name token.Token, Value Expr",
+		"Slice    : Object Expr, Bracket token.Token, Start Expr, End Expr",
 		"Super    : Keyword token.Token, Method token.Token",
 		"This     : Keyword token.Token, EnvIndex int, EnvDepth int",
 		"Unary    : Operator token.Token, Right Expr",
+		"Update   : Target Expr, Operator token.Token, Prefix bool",
 		"Variable : Name token.Token, EnvIndex int, EnvDepth int",
 	}
 
-	defineAst("ast/expr.go", "Expr", exprNodes)
+	defineAst("ast/expr.go", "Expr", exprNodes, "github.com/0xsuk/golox/token", "github.com/0xsuk/golox/value")
 
 	stmtNodes := []string{
 		"Block      : Statements []Stmt",
 		"Class      : Name token.Token, Superclass VariableExpr, Methods []FunctionStmt",
 		"Expression : Expression Expr",
+		"ForIn      : Keyword token.Token, Name token.Token, Iterable Expr, Body Stmt",
 		"Function   : Name token.Token, Params []token.Token, Body []Stmt",
+		"Match      : Keyword token.Token, Subject Expr, Cases []MatchCase",
 		"If         : Condition Expr, ThenBranch Stmt, ElseBranch Stmt",
 		"Print      : Expression Expr",
 		"Return     : Keyword token.Token, Value Expr",
 		"Continue   : Token token.Token",
 		"Break      : Token token.Token",
+		"Throw      : Keyword token.Token, Value Expr",
+		"Try        : Keyword token.Token, Body []Stmt, Name token.Token, Catch []Stmt, Finally []Stmt",
 		"Var        : Name token.Token, Initializer Expr",
 		"While      : Condition Expr, Body Stmt",
 	}
 
-	defineAst("ast/stmt.go", "Stmt", stmtNodes)
+	defineAst("ast/stmt.go", "Stmt", stmtNodes, "github.com/0xsuk/golox/token")
+
+	patternNodes := []string{
+		"Literal  : Token token.Token, Value value.Value",
+		"Wildcard : Token token.Token",
+		"Binding  : Name token.Token, EnvIndex int, EnvDepth int",
+		"List     : Bracket token.Token, Elements []Pattern",
+		"Map      : Brace token.Token, Keys []value.Value, Values []Pattern",
+		"Instance : Class VariableExpr, Fields []token.Token, Patterns []Pattern",
+	}
+
+	defineAst("ast/pattern.go", "Pattern", patternNodes, "github.com/0xsuk/golox/token", "github.com/0xsuk/golox/value")
 }
 
-func defineAst(path string, basename string, types []string) {
+func defineAst(path string, basename string, types []string, imports ...string) {
 	f, err := os.Create(path)
 	if err != nil {
 		panic(err)
@@ -49,7 +74,15 @@ func defineAst(path string, basename string, types []string) {
 	defer f.Close()
 
 	f.WriteString("package ast\n")
-	f.WriteString("import \"github.com/0xsuk/golox/token\"\n")
+	if len(imports) == 1 {
+		f.WriteString("import \"" + imports[0] + "\"\n")
+	} else {
+		f.WriteString("import (\n")
+		for _, imp := range imports {
+			f.WriteString("\"" + imp + "\"\n")
+		}
+		f.WriteString(")\n")
+	}
 
 	f.WriteString("type " + basename + " interface {\n")
 	f.WriteString("Accept(visitor " + basename + "Visitor)\n")
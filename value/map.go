@@ -0,0 +1,73 @@
+package value
+
+// Map backs Lox map values. Keys iterate in insertion order.
+type Map struct {
+	keys    []Value
+	entries map[Value]Value
+}
+
+func NewMap() *Map {
+	return &Map{keys: make([]Value, 0), entries: make(map[Value]Value)}
+}
+
+// IsHashable reports whether v can be used as a map key. Only nil, booleans,
+// numbers and strings are, and they compare the same way == does in Lox.
+// NaN is rejected since it never equals itself, it could be stored but
+// never found again.
+func (v Value) IsHashable() bool {
+	if v.kind == NumberKind {
+		return v.num == v.num
+	}
+	return v.kind != ObjectKind || v.IsString()
+}
+
+func (m *Map) Len() int {
+	return len(m.keys)
+}
+
+func (m *Map) Get(key Value) (Value, bool) {
+	v, ok := m.entries[key]
+	return v, ok
+}
+
+func (m *Map) Has(key Value) bool {
+	_, ok := m.entries[key]
+	return ok
+}
+
+// Set adds or overwrites an entry, key must be hashable
+func (m *Map) Set(key Value, val Value) {
+	if _, ok := m.entries[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.entries[key] = val
+}
+
+// Delete removes an entry and reports whether it was there
+func (m *Map) Delete(key Value) bool {
+	if _, ok := m.entries[key]; !ok {
+		return false
+	}
+	delete(m.entries, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+func (m *Map) Keys() []Value {
+	keys := make([]Value, len(m.keys))
+	copy(keys, m.keys)
+	return keys
+}
+
+func (m *Map) Values() []Value {
+	values := make([]Value, len(m.keys))
+	for i, k := range m.keys {
+		values[i] = m.entries[k]
+	}
+	return values
+}
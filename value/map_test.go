@@ -0,0 +1,51 @@
+package value
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIsHashable(t *testing.T) {
+	tests := []struct {
+		v    Value
+		want bool
+	}{
+		{Nil, true},
+		{Bool(true), true},
+		{Number(1.5), true},
+		{Number(math.Inf(1)), true},
+		{Number(math.NaN()), false},
+		{Object("key"), true},
+		{Object(NewMap()), false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.v.IsHashable(); got != tt.want {
+			t.Errorf("%v.IsHashable() = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestMapOrderAndKeys(t *testing.T) {
+	m := NewMap()
+	m.Set(Object("b"), Number(1))
+	m.Set(Number(0), Number(2))
+	m.Set(Bool(true), Number(3))
+	m.Set(Number(math.Copysign(0, -1)), Number(4)) // -0 == 0
+	m.Set(Object("b"), Number(5))
+
+	if m.Len() != 3 {
+		t.Fatalf("Len() = %v, want 3", m.Len())
+	}
+	if v, _ := m.Get(Number(0)); v.AsNumber() != 4 {
+		t.Errorf("Get(0) = %v, want 4", v)
+	}
+
+	if !m.Delete(Object("b")) || m.Has(Object("b")) {
+		t.Errorf("Delete(\"b\") didn't remove the key")
+	}
+	keys := m.Keys()
+	if len(keys) != 2 || !keys[0].Equals(Number(0)) || !keys[1].Equals(Bool(true)) {
+		t.Errorf("Keys() = %v, want [0 true]", keys)
+	}
+}
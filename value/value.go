@@ -0,0 +1,117 @@
+package value
+
+import "fmt"
+
+// Kind tells which member of the Value union is in use
+type Kind uint8
+
+const (
+	NilKind Kind = iota
+	BoolKind
+	NumberKind
+	ObjectKind
+)
+
+// Value is a tagged union of every Lox value. Booleans and numbers are kept
+// unboxed in num, everything else (strings, functions, instances...) lives in obj.
+type Value struct {
+	kind Kind
+	num  float64
+	obj  interface{}
+}
+
+// Nil is the zero Value
+var Nil = Value{}
+
+func Bool(b bool) Value {
+	if b {
+		return Value{kind: BoolKind, num: 1}
+	}
+	return Value{kind: BoolKind}
+}
+
+func Number(n float64) Value {
+	return Value{kind: NumberKind, num: n}
+}
+
+func Object(o interface{}) Value {
+	return Value{kind: ObjectKind, obj: o}
+}
+
+func (v Value) Kind() Kind {
+	return v.kind
+}
+
+func (v Value) IsNil() bool {
+	return v.kind == NilKind
+}
+
+func (v Value) IsBool() bool {
+	return v.kind == BoolKind
+}
+
+func (v Value) IsNumber() bool {
+	return v.kind == NumberKind
+}
+
+func (v Value) IsObject() bool {
+	return v.kind == ObjectKind
+}
+
+func (v Value) IsString() bool {
+	_, ok := v.obj.(string)
+	return v.kind == ObjectKind && ok
+}
+
+func (v Value) AsBool() bool {
+	return v.num != 0
+}
+
+func (v Value) AsNumber() float64 {
+	return v.num
+}
+
+func (v Value) AsObject() interface{} {
+	return v.obj
+}
+
+func (v Value) AsString() string {
+	s, _ := v.obj.(string)
+	return s
+}
+
+// IsTruthy follows Lox semantics: only nil and false are falsey
+func (v Value) IsTruthy() bool {
+	switch v.kind {
+	case NilKind:
+		return false
+	case BoolKind:
+		return v.AsBool()
+	}
+	return true
+}
+
+func (v Value) Equals(other Value) bool {
+	if v.kind != other.kind {
+		return false
+	}
+	switch v.kind {
+	case NilKind:
+		return true
+	case BoolKind, NumberKind:
+		return v.num == other.num
+	}
+	return v.obj == other.obj
+}
+
+func (v Value) String() string {
+	switch v.kind {
+	case NilKind:
+		return "nil"
+	case BoolKind:
+		return fmt.Sprint(v.AsBool())
+	case NumberKind:
+		return fmt.Sprint(v.num)
+	}
+	return fmt.Sprint(v.obj)
+}